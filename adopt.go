@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// ServiceInfo is a summary of any service registered with SCM
+type ServiceInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Status      string `json:"status"`
+	StartType   string `json:"startType"`
+	PID         int    `json:"pid"`
+	Managed     bool   `json:"managed"`
+}
+
+// ListAllSystemServices enumerates every service known to SCM, including those not managed by this tool
+func (wsm *WindowsServiceManager) ListAllSystemServices() ([]ServiceInfo, error) {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	var infos []ServiceInfo
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		names, err := scm.ListServices()
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+
+		infos = make([]ServiceInfo, 0, len(names))
+		for _, name := range names {
+			info := ServiceInfo{Name: name, DisplayName: name, Status: "error"}
+			_, info.Managed = wsm.services[name]
+
+			windowsService, err := scm.OpenService(name)
+			if err != nil {
+				infos = append(infos, info)
+				continue
+			}
+
+			if config, err := windowsService.Config(); err == nil {
+				if config.DisplayName != "" {
+					info.DisplayName = config.DisplayName
+				}
+				info.StartType = startTypeName(config)
+			}
+			if status, err := windowsService.Query(); err == nil {
+				info.Status = serviceStateName(status.State)
+				info.PID = int(status.ProcessId)
+			}
+			windowsService.Close()
+
+			infos = append(infos, info)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// AdoptService takes a pre-existing Windows service under management so it can be
+// started, stopped and configured from the UI. Adopted services are never deleted.
+func (wsm *WindowsServiceManager) AdoptService(serviceName string) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	if _, exists := wsm.services[serviceName]; exists {
+		return fmt.Errorf("service is already managed: %s", serviceName)
+	}
+
+	var service *Service
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceName)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		config, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+
+		exePath, args := splitBinaryPath(config.BinaryPathName)
+
+		service = &Service{
+			ID:             serviceName,
+			Name:           config.DisplayName,
+			Description:    config.Description,
+			ExePath:        exePath,
+			Args:           args,
+			LoadOrderGroup: config.LoadOrderGroup,
+			Account:        config.ServiceStartName,
+			Dependencies:   config.Dependencies,
+			AutoStart:      config.StartType == mgr.StartAutomatic,
+			StartType:      startTypeName(config),
+			Adopted:        true,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+		if service.Name == "" {
+			service.Name = serviceName
+		}
+
+		if status, err := windowsService.Query(); err == nil {
+			service.Status = serviceStateName(status.State)
+			service.PID = int(status.ProcessId)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	wsm.services[serviceName] = service
+	wsm.statusCache.Set(serviceName, service.Status, service.PID)
+	wsm.saveServices()
+
+	// Emit service list update event
+	wsm.emitServicesUpdated()
+
+	return nil
+}
+
+// ReleaseService stops managing an adopted service without touching the service itself
+func (wsm *WindowsServiceManager) ReleaseService(serviceID string) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if !service.Adopted {
+		return fmt.Errorf("service %s was created by Windows Service Manager, delete it instead", serviceID)
+	}
+
+	delete(wsm.services, serviceID)
+	wsm.statusCache.Remove(serviceID)
+	wsm.saveServices()
+
+	// Emit service list update event
+	wsm.emitServicesUpdated()
+
+	return nil
+}
+
+// splitBinaryPath separates an SCM binary path into the executable and its arguments
+func splitBinaryPath(binaryPath string) (string, string) {
+	args, err := windows.DecomposeCommandLine(binaryPath)
+	if err != nil || len(args) == 0 {
+		return binaryPath, ""
+	}
+
+	exePath := args[0]
+	rest := strings.TrimSpace(binaryPath)
+	if strings.HasPrefix(rest, `"`) {
+		if end := strings.Index(rest[1:], `"`); end >= 0 {
+			rest = rest[end+2:]
+		}
+	} else {
+		rest = strings.TrimPrefix(rest, exePath)
+	}
+
+	return exePath, strings.TrimSpace(rest)
+}
+
+// CreateServiceFromExisting creates a managed service that runs the same program as an existing
+// Windows service, copying its command line, start type, account, dependencies and description.
+// The existing service is left alone. Programs written as native services cannot run under the
+// built-in wrapper; take those under management with AdoptService instead.
+func (wsm *WindowsServiceManager) CreateServiceFromExisting(existingServiceName string) (*Service, error) {
+	var config ServiceConfig
+	var startType string
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(existingServiceName)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		existing, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+		if existing.ServiceType&windows.SERVICE_WIN32_OWN_PROCESS == 0 {
+			return fmt.Errorf("service %s is a driver or shares its process with other services and cannot be recreated", existingServiceName)
+		}
+
+		name := existing.DisplayName
+		if name == "" {
+			name = existingServiceName
+		}
+		config = ServiceConfig{
+			Name:           name + " (WSM)",
+			Description:    existing.Description,
+			LoadOrderGroup: existing.LoadOrderGroup,
+			Dependencies:   existing.Dependencies,
+		}
+		startType = startTypeName(existing)
+
+		// A service created by this tool runs the wrapper, the real target is in its Parameters key
+		if readServiceParameters(existingServiceName, "ManagedBy")["ManagedBy"] == managedByMarker {
+			wrapped, err := LoadServiceConfigFromRegistry(existingServiceName)
+			if err != nil {
+				return err
+			}
+			config.ExePath = wrapped.ExePath
+			config.ArgsList = wrapped.ArgsList
+			config.WorkingDir = wrapped.WorkingDir
+		} else {
+			config.ExePath, config.ArgsList = parseImagePath(existing.BinaryPathName)
+		}
+
+		// Passwords cannot be read back, so only accounts that do not need one are carried over
+		if isBuiltinServiceAccount(existing.ServiceStartName) {
+			config.Account = existing.ServiceStartName
+		} else if existing.ServiceStartName != "" {
+			fmt.Printf("Warning: %s runs as %s, the new service runs as LocalSystem until its account is set\n", existingServiceName, existing.ServiceStartName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := wsm.CreateService(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if startType != "auto" && startType != "unknown" {
+		if err := wsm.SetServiceStartType(service.ID, startType); err != nil {
+			fmt.Printf("Warning: failed to set start type of %s: %v\n", service.ID, err)
+		}
+	}
+
+	return service, nil
+}
+
+// isBuiltinServiceAccount reports whether account is one of the built-in accounts services run as
+func isBuiltinServiceAccount(account string) bool {
+	for _, builtin := range builtinServiceAccounts {
+		if strings.EqualFold(account, builtin) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseImagePath splits a service ImagePath into the executable and its arguments.
+// Unquoted paths with spaces are resolved the way SCM does, by trying ever longer
+// space-separated prefixes until one names an existing file.
+func parseImagePath(imagePath string) (string, []string) {
+	imagePath = strings.TrimPrefix(strings.TrimSpace(imagePath), `\??\`)
+
+	if !strings.HasPrefix(imagePath, `"`) {
+		tokens := strings.Split(imagePath, " ")
+		for i := 1; i <= len(tokens); i++ {
+			candidate := strings.Join(tokens[:i], " ")
+			for _, path := range []string{candidate, candidate + ".exe"} {
+				if info, err := os.Stat(expandServicePath(path)); err == nil && !info.IsDir() {
+					args, _ := windows.DecomposeCommandLine(strings.Join(tokens[i:], " "))
+					return path, args
+				}
+			}
+		}
+	}
+
+	args, err := windows.DecomposeCommandLine(imagePath)
+	if err != nil || len(args) == 0 {
+		return imagePath, nil
+	}
+	return args[0], args[1:]
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 	"unsafe"
@@ -21,29 +24,112 @@ import (
 
 // Service represents a background service
 type Service struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	ExePath    string    `json:"exePath"`
-	Args       string    `json:"args"`
-	WorkingDir string    `json:"workingDir"`
-	Status     string    `json:"status"` // "running", "stopped", "error"
-	PID        int       `json:"pid"`
-	AutoStart  bool      `json:"autoStart"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	ExePath        string    `json:"exePath"`
+	Args           string    `json:"args"`
+	WorkingDir     string    `json:"workingDir"`
+	LogPath        string    `json:"logPath"`
+	ErrorLogPath   string    `json:"errorLogPath"` // stderr log, empty when stderr goes to LogPath
+	LoadOrderGroup string    `json:"loadOrderGroup"`
+	Account        string    `json:"account"`
+	Dependencies   []string  `json:"dependencies"`
+	Tags           []string  `json:"tags"`
+	Status         string    `json:"status"` // "running", "stopped", "paused", "error"
+	Health         string    `json:"health"` // "healthy" or "unhealthy"; empty without a health check
+	PID            int       `json:"pid"`
+	AutoStart      bool      `json:"autoStart"`
+	StartType      string    `json:"startType"` // "auto", "delayed", "manual" or "disabled"
+	Critical       bool      `json:"critical"`
+	Adopted        bool      `json:"adopted"` // pre-existing Windows service taken under management
+	StartedAt      time.Time `json:"startedAt"`
+	UptimeSec      int64     `json:"uptimeSec"`    // seconds since StartedAt while running
+	LastExitCode   *int      `json:"lastExitCode"` // exit code of the target's last run, nil if it never exited
+	LastExitTime   time.Time `json:"lastExitTime"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
 
 // ServiceConfig is the configuration for creating a new service
 type ServiceConfig struct {
-	Name       string `json:"name"`
+	Name           string `json:"name"`
+	ServiceName    string `json:"serviceName"` // internal SCM name; generated from Name when empty or taken
+	Description    string `json:"description"` // shown in services.msc; a generic text when empty
+	ExePath        string `json:"exePath"`
+	Args           string `json:"args"`
+	WorkingDir     string `json:"workingDir"`
+	LogPath        string `json:"logPath"`      // defaults to a per-service file under ProgramData
+	ErrorLogPath   string `json:"errorLogPath"` // separate file for stderr; empty sends stderr to LogPath
+	LoadOrderGroup string `json:"loadOrderGroup"`
+
+	// ArgsList holds the arguments one per entry and is passed to the executable as is.
+	// When it is empty, Args is parsed with Windows command line quoting rules instead.
+	ArgsList []string `json:"argsList"`
+
+	// LogMaxSizeMB rotates the log once it grows past this size (default 10);
+	// LogMaxBackups is how many rotated files are kept (default 3 when unset, 0 keeps none)
+	LogMaxSizeMB  int  `json:"logMaxSizeMB"`
+	LogMaxBackups *int `json:"logMaxBackups,omitempty"`
+
+	// LogEncoding is the encoding the target writes its output in, such as "shift_jis" or "cp932".
+	// The log panel transcodes it to UTF-8; empty means the output is shown as is.
+	LogEncoding string `json:"logEncoding"`
+
+	// ListenPort is the TCP port the target listens on; when set, StartService refuses to start
+	// the service while another process holds the port
+	ListenPort int `json:"listenPort"`
+
+	// StopTimeoutSec is how long the target may take to exit after Ctrl-C/WM_CLOSE before it is killed (default 10);
+	// stopping waits for SCM at least this long plus a margin, and never less than 30 seconds.
+	// StartTimeoutSec is how long starting waits for the service to run (default 30).
+	StopTimeoutSec  int `json:"stopTimeoutSec"`
+	StartTimeoutSec int `json:"startTimeoutSec"`
+
+	// RestartOnExit restarts the target when it exits on its own, up to MaxRestarts times (default 3)
+	RestartOnExit bool `json:"restartOnExit"`
+	MaxRestarts   int  `json:"maxRestarts"`
+
+	// ExtraCommands are companion processes started and stopped together with the main executable
+	ExtraCommands []CommandSpec `json:"extraCommands"`
+
+	// Env holds extra environment variables (e.g. NODE_ENV=production) set for the
+	// executable on top of the environment the service inherits
+	Env map[string]string `json:"env"`
+
+	// Account runs the service as this user (.\user or DOMAIN\user); empty means LocalSystem.
+	// Password is passed straight to SCM and never stored.
+	Account  string `json:"account"`
+	Password string `json:"password"`
+
+	// Dependencies are services that must be running before this one starts
+	Dependencies []string `json:"dependencies"`
+
+	// Tags group services (e.g. "dev", "db") for filtering and batch operations
+	Tags []string `json:"tags"`
+
+	// IdleTimeout stops the service after this long without activity (0 disables it).
+	// IdleCriterion selects what counts as activity: "log" (default) for output written
+	// by the target, or "cpu" for CPU time consumed by the target.
+	IdleTimeout   time.Duration `json:"idleTimeout"`
+	IdleCriterion string        `json:"idleCriterion"`
+
+	// HealthCheckType probes the running service over "tcp" (HealthCheckTarget is host:port) or
+	// "http" (HealthCheckTarget is a URL answering below 400) every HealthCheckIntervalSec (default 30)
+	HealthCheckType        string `json:"healthCheckType"`
+	HealthCheckTarget      string `json:"healthCheckTarget"`
+	HealthCheckIntervalSec int    `json:"healthCheckIntervalSec"`
+}
+
+// CommandSpec describes an additional process run by a service
+type CommandSpec struct {
 	ExePath    string `json:"exePath"`
 	Args       string `json:"args"`
 	WorkingDir string `json:"workingDir"`
-	LogPath    string 
 }
 
 type ThemeData struct {
-	Theme string `json:"theme"` // "light" or "dark"
+	Theme string `json:"theme"` // "light", "dark" or "auto"
 }
 
 type tailerInfo struct {
@@ -57,13 +143,38 @@ type App struct {
 	environmentManager *EnvironmentManager
 	logTailers         map[string]*tailerInfo // serviceID -> tailer info
 	logTailersLock     sync.Mutex
+
+	logTimestampPattern atomic.Pointer[regexp.Regexp] // read by tailers while the UI changes it; nil until set
+	settings            AppSettings
+	settingsMutex       sync.Mutex
+
+	themeWatcherCancel context.CancelFunc
+	themeWatcherMutex  sync.Mutex
+
+	statusServer      *http.Server
+	statusToken       string
+	statusServerMutex sync.Mutex
 }
 
 func NewApp() *App {
+	migrateLegacyConfig()
+	settings := loadSettings()
+
+	serviceManager := NewWindowsServiceManager()
+	serviceManager.SetNamePrefix(settings.ServiceNamePrefix)
+	serviceManager.SetNotifyOnCrash(settings.NotifyOnCrash)
+	serviceManager.SetLogDir(settings.LogDir)
+
+	environmentManager := NewEnvironmentManager()
+	if err := environmentManager.SetMaxPathLength(settings.MaxPathLength); err != nil {
+		fmt.Printf("Warning: ignoring saved PATH length limit: %v\n", err)
+	}
+
 	return &App{
-		serviceManager:     NewWindowsServiceManager(),
-		environmentManager: NewEnvironmentManager(),
+		serviceManager:     serviceManager,
+		environmentManager: environmentManager,
 		logTailers:         make(map[string]*tailerInfo),
+		settings:           settings,
 	}
 }
 
@@ -71,19 +182,36 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.serviceManager.SetContext(ctx)
-	a.serviceManager.loadServices()
+	if err := a.serviceManager.loadServices(); err != nil {
+		// Services still registered in SCM are recovered by ReconcileServices below
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if _, err := a.serviceManager.ReconcileServices(); err != nil {
+		fmt.Printf("Warning: failed to recover services: %v\n", err)
+	}
+	a.serviceManager.reconcileRunningServices()
+	a.restoreMonitoredServices()
+	a.serviceManager.StartStatusWatcher()
+	a.serviceManager.StartHealthChecker()
+	if a.GetTheme() == themeAuto {
+		a.startThemeWatcher()
+	}
+}
+
+// shutdown is called when the application is closing
+func (a *App) shutdown(ctx context.Context) {
+	a.serviceManager.StopStatusWatcher()
+	a.serviceManager.StopHealthChecker()
+	a.stopThemeWatcher()
+	a.StopStatusServer()
 }
 
 // getThemeConfigPath returns the path to the theme config file
 func (a *App) getThemeConfigPath() (string, error) {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "Windows Service Manager.exe", "theme.json"), nil
+	return configFilePath("theme.json")
 }
 
-// GetTheme returns the saved theme ("light" or "dark"), defaulting to "light"
+// GetTheme returns the saved theme ("light", "dark" or "auto"), defaulting to "light"
 func (a *App) GetTheme() string {
 	path, err := a.getThemeConfigPath()
 	if err != nil {
@@ -101,7 +229,7 @@ func (a *App) GetTheme() string {
 		return "light"
 	}
 
-	if themeData.Theme != "light" && themeData.Theme != "dark" {
+	if !isValidTheme(themeData.Theme) {
 		return "light"
 	}
 	return themeData.Theme
@@ -109,7 +237,7 @@ func (a *App) GetTheme() string {
 
 // SetTheme saves the theme preference
 func (a *App) SetTheme(theme string) error {
-	if theme != "light" && theme != "dark" {
+	if !isValidTheme(theme) {
 		theme = "light" // fallback
 	}
 
@@ -129,7 +257,16 @@ func (a *App) SetTheme(theme string) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	if theme == themeAuto {
+		a.startThemeWatcher()
+	} else {
+		a.stopThemeWatcher()
+	}
+	return nil
 }
 
 // GetServices returns the list of all services
@@ -141,11 +278,31 @@ func (a *App) GetServices() []*Service {
 	return services
 }
 
+// GetCacheStats returns the hit, miss and eviction counts of the service status cache
+func (a *App) GetCacheStats() CacheStats {
+	return a.serviceManager.statusCache.Stats()
+}
+
+// ReconcileServices re-adds services created by this tool that are missing from the service list
+func (a *App) ReconcileServices() ([]*Service, error) {
+	return a.serviceManager.ReconcileServices()
+}
+
+// ValidateServiceConfig checks a service configuration without creating the service
+func (a *App) ValidateServiceConfig(config ServiceConfig) []ValidationError {
+	return a.serviceManager.ValidateServiceConfig(config)
+}
+
 // CreateService creates a new service
 func (a *App) CreateService(config ServiceConfig) (*Service, error) {
 	return a.serviceManager.CreateService(config)
 }
 
+// EditService updates the executable, arguments and working directory of a stopped service
+func (a *App) EditService(serviceID string, config ServiceConfig) error {
+	return a.serviceManager.EditService(serviceID, config)
+}
+
 // StartService starts a service
 func (a *App) StartService(serviceID string) error {
 	return a.serviceManager.StartService(serviceID)
@@ -156,15 +313,278 @@ func (a *App) StopService(serviceID string) error {
 	return a.serviceManager.StopService(serviceID)
 }
 
-// DeleteService deletes a service
-func (a *App) DeleteService(serviceID string) error {
+// ForceStopService terminates a service stuck in the starting or stopping state
+func (a *App) ForceStopService(serviceID string) error {
+	return a.serviceManager.ForceStopService(serviceID)
+}
+
+// RestartService stops and starts a service in one step
+func (a *App) RestartService(serviceID string) error {
+	return a.serviceManager.RestartService(serviceID)
+}
+
+// PauseService pauses a service that supports pause and continue
+func (a *App) PauseService(serviceID string) error {
+	return a.serviceManager.PauseService(serviceID)
+}
+
+// ContinueService resumes a paused service
+func (a *App) ContinueService(serviceID string) error {
+	return a.serviceManager.ContinueService(serviceID)
+}
+
+// DeleteService deletes a service; force also deletes services that do not run through the wrapper.
+// A service that is not stopped needs the token from RequestServiceDeletion.
+func (a *App) DeleteService(serviceID string, force bool, token string) error {
 	// Stop any active log monitoring for this service
 	a.StopMonitoringService(serviceID)
-	return a.serviceManager.DeleteService(serviceID)
+	return a.serviceManager.DeleteService(serviceID, force, token)
+}
+
+// DeleteServiceAndData deletes a service together with its leftover registry key and log files
+func (a *App) DeleteServiceAndData(serviceID string, force bool, token string) (*PurgeReport, error) {
+	a.StopMonitoringService(serviceID)
+	return a.serviceManager.DeleteServiceAndData(serviceID, force, token)
+}
+
+// RequestServiceDeletion returns what deleting a service removes and a token that confirms it
+func (a *App) RequestServiceDeletion(serviceID string) (*DeletionRequest, error) {
+	return a.serviceManager.RequestServiceDeletion(serviceID)
+}
+
+// ForceDeleteService deletes a service, terminating its process if it won't stop
+func (a *App) ForceDeleteService(serviceID string) error {
+	a.StopMonitoringService(serviceID)
+	return a.serviceManager.ForceDeleteService(serviceID)
+}
+
+// StartServices starts several services and returns serviceID -> error message (empty on success)
+func (a *App) StartServices(serviceIDs []string) map[string]string {
+	return a.serviceManager.StartServices(serviceIDs)
+}
+
+// StartServicesByTag starts every service carrying tag and returns serviceID -> error message (empty on success)
+func (a *App) StartServicesByTag(tag string) map[string]string {
+	return a.serviceManager.StartServicesByTag(tag)
+}
+
+// StopServicesByTag stops every service carrying tag and returns serviceID -> error message (empty on success)
+func (a *App) StopServicesByTag(tag string) map[string]string {
+	return a.serviceManager.StopServicesByTag(tag)
+}
+
+// SetServiceTags replaces the tags of a service
+func (a *App) SetServiceTags(serviceID string, tags []string) error {
+	return a.serviceManager.SetServiceTags(serviceID, tags)
+}
+
+// GetServicesByTag returns the services carrying tag
+func (a *App) GetServicesByTag(tag string) []*Service {
+	return a.serviceManager.GetServicesByTag(tag)
+}
+
+// GetServiceProcessTree returns the process of a running service and all its descendants
+func (a *App) GetServiceProcessTree(serviceID string) ([]ProcessInfo, error) {
+	return a.serviceManager.GetServiceProcessTree(serviceID)
+}
+
+// ReloadService restarts a service so it runs its rebuilt executable
+func (a *App) ReloadService(serviceID string) error {
+	return a.serviceManager.ReloadService(serviceID)
+}
+
+// StartAllServices starts every managed service and returns serviceID -> error message (empty on success)
+func (a *App) StartAllServices() map[string]string {
+	return a.serviceManager.StartAllServices()
+}
+
+// StopAllServices stops every managed service and returns serviceID -> error message (empty on success)
+func (a *App) StopAllServices() map[string]string {
+	return a.serviceManager.StopAllServices()
+}
+
+// PauseAllServices pauses every managed service and returns serviceID -> error message (empty on success)
+func (a *App) PauseAllServices() map[string]string {
+	return a.serviceManager.PauseAllServices()
+}
+
+// QueryServices returns the services matching filter
+func (a *App) QueryServices(filter ServiceFilter) ([]*Service, error) {
+	return a.serviceManager.QueryServices(filter)
+}
+
+// StopServices stops several services and returns serviceID -> error message (empty on success)
+func (a *App) StopServices(serviceIDs []string) map[string]string {
+	return a.serviceManager.StopServices(serviceIDs)
+}
+
+// DeleteServices deletes several services and returns serviceID -> error message (empty on success)
+func (a *App) DeleteServices(serviceIDs []string) map[string]string {
+	results := a.serviceManager.DeleteServices(serviceIDs)
+	for serviceID, message := range results {
+		if message == "" {
+			a.StopMonitoringService(serviceID)
+		}
+	}
+	return results
+}
+
+// ListAllSystemServices lists every Windows service, managed or not
+func (a *App) ListAllSystemServices() ([]ServiceInfo, error) {
+	return a.serviceManager.ListAllSystemServices()
+}
+
+// CreateServiceFromExisting creates a managed copy of an existing Windows service
+func (a *App) CreateServiceFromExisting(existingServiceName string) (*Service, error) {
+	return a.serviceManager.CreateServiceFromExisting(existingServiceName)
+}
+
+// AdoptService brings an existing Windows service under management
+func (a *App) AdoptService(serviceName string) error {
+	return a.serviceManager.AdoptService(serviceName)
+}
+
+// ReleaseService stops managing an adopted service without deleting it
+func (a *App) ReleaseService(serviceID string) error {
+	a.StopMonitoringService(serviceID)
+	return a.serviceManager.ReleaseService(serviceID)
+}
+
+// GetServiceResourceUsage returns the memory and CPU usage of a running service
+func (a *App) GetServiceResourceUsage(serviceID string) (*ResourceUsage, error) {
+	return a.serviceManager.GetServiceResourceUsage(serviceID)
+}
+
+// SetServiceCritical marks a service as critical, guarding it against casual stops and deletes
+func (a *App) SetServiceCritical(serviceID string, critical bool) error {
+	return a.serviceManager.SetServiceCritical(serviceID, critical)
+}
+
+// ConfirmCriticalAction performs a stop, restart or delete of a critical service using the token
+// sent with the critical-action-requested event
+func (a *App) ConfirmCriticalAction(token string) error {
+	pending, err := a.serviceManager.ConfirmCriticalAction(token)
+	if err == nil && pending.action != "stop" && pending.action != "restart" {
+		a.StopMonitoringService(pending.serviceID)
+	}
+	return err
+}
+
+// GetServiceDetails returns the live SCM configuration of a service
+func (a *App) GetServiceDetails(serviceID string) (*ServiceDetails, error) {
+	return a.serviceManager.GetServiceDetails(serviceID)
+}
+
+// GetServiceUptime returns how long a service has been running, in seconds
+func (a *App) GetServiceUptime(serviceID string) (int64, error) {
+	uptime, err := a.serviceManager.GetServiceUptime(serviceID)
+	return int64(uptime / time.Second), err
+}
+
+// GetServiceConfig returns the full current configuration of a service for editing
+func (a *App) GetServiceConfig(serviceID string) (*FullServiceConfig, error) {
+	return a.serviceManager.GetServiceConfig(serviceID)
+}
+
+// GetServiceImagePath returns the raw ImagePath registry value of a service
+func (a *App) GetServiceImagePath(serviceID string) (string, error) {
+	return a.serviceManager.GetServiceImagePath(serviceID)
+}
+
+// PreviewService shows what CreateService would register for config without creating anything
+func (a *App) PreviewService(config ServiceConfig) (*ServicePreview, error) {
+	return a.serviceManager.PreviewService(config)
+}
+
+// GetServiceCommandLine returns the wrapper command line, the target command and the working directory of a service
+func (a *App) GetServiceCommandLine(serviceID string) (*ServiceCommandLine, error) {
+	return a.serviceManager.GetServiceCommandLine(serviceID)
+}
+
+// ServiceLogPath is where a service logs and whether the log file exists yet
+type ServiceLogPath struct {
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+// GetServiceLogPath returns the log file path of a service
+func (a *App) GetServiceLogPath(serviceID string) (*ServiceLogPath, error) {
+	path, exists, err := a.serviceManager.GetServiceLogPath(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceLogPath{Path: path, Exists: exists}, nil
+}
+
+// GetLogDiskUsage returns the space taken by a service's logs and the free space on their drive
+func (a *App) GetLogDiskUsage(serviceID string) (*LogDiskUsage, error) {
+	logPath, _, err := a.serviceManager.GetServiceLogPath(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	used, free, err := a.serviceManager.GetLogDiskUsage(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	return &LogDiskUsage{LogPath: logPath, UsedBytes: used, FreeBytes: free}, nil
+}
+
+// SetServiceLogPath changes the log file of a service, effective at its next start
+func (a *App) SetServiceLogPath(serviceID, path string) error {
+	return a.serviceManager.SetServiceLogPath(serviceID, path)
+}
+
+// CopyToClipboard puts text on the clipboard
+func (a *App) CopyToClipboard(text string) error {
+	if err := runtime.ClipboardSetText(a.ctx, text); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %v", err)
+	}
+	return nil
+}
+
+// SetServiceDependencies sets the services that must start before this one
+func (a *App) SetServiceDependencies(serviceID string, dependencies []string) error {
+	return a.serviceManager.SetServiceDependencies(serviceID, dependencies)
+}
+
+// SetServiceRecovery configures what happens when a service fails
+func (a *App) SetServiceRecovery(serviceID string, config RecoveryConfig) error {
+	return a.serviceManager.SetServiceRecovery(serviceID, config)
+}
+
+// SetServiceLoadOrderGroup changes the load-order group of a service
+func (a *App) SetServiceLoadOrderGroup(serviceID, group string) error {
+	return a.serviceManager.SetServiceLoadOrderGroup(serviceID, group)
+}
+
+// RepairServiceRegistry rewrites missing or divergent registry values of a service
+func (a *App) RepairServiceRegistry(serviceID string) (RepairReport, error) {
+	return a.serviceManager.RepairServiceRegistry(serviceID)
+}
+
+// FindOrphanedWrappers lists service-wrapper processes that no longer match their service
+func (a *App) FindOrphanedWrappers() ([]OrphanInfo, error) {
+	return a.serviceManager.FindOrphanedWrappers()
+}
+
+// KillOrphanedWrapper terminates an orphaned service-wrapper process
+func (a *App) KillOrphanedWrapper(pid int) error {
+	return a.serviceManager.KillOrphanedWrapper(pid)
 }
 
 // StartMonitoringLog begins tailing the service's log file and emits lines to the frontend.
 func (a *App) StartMonitoringService(serviceID string) error {
+	return a.startMonitoring(serviceID, 0)
+}
+
+// StartMonitoringServiceFromEnd begins tailing like StartMonitoringService, but first emits
+// the last lastN lines already in the log so the frontend has recent context.
+func (a *App) StartMonitoringServiceFromEnd(serviceID string, lastN int) error {
+	return a.startMonitoring(serviceID, lastN)
+}
+
+// startMonitoring starts a tailer for a service, seeding it with up to lastN existing lines
+func (a *App) startMonitoring(serviceID string, lastN int) error {
 	a.logTailersLock.Lock()
 	defer a.logTailersLock.Unlock()
 
@@ -189,43 +609,83 @@ func (a *App) StartMonitoringService(serviceID string) error {
 
 	go func() {
         defer close(done)
-        a.tailLogFile(ctx, serviceID, logPath)
+        a.tailLogFile(ctx, serviceID, logPath, lastN)
     }()
+
+	a.saveMonitoredServices()
 	return nil
 }
 
 // GetLogContent returns all current lines from the service's log file.
 func (a *App) GetLogContent(serviceID string) ([]string, error) {
-    logPath, _, err := a.serviceManager.GetServiceLogPath(serviceID)
-    if err != nil {
-        return nil, err
-    }
-    return a.readAllLines(logPath)
+	logPath, _, err := a.serviceManager.GetServiceLogPath(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	return a.readAllLines(logPath, a.logLineDecoder(serviceID))
 }
 
-// readAllLines is a helper that reads a file and returns its lines.
-func (a *App) readAllLines(path string) ([]string, error) {
-    file, err := os.Open(path)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
+// GetErrorLogContent returns all current lines from the service's separate stderr log
+func (a *App) GetErrorLogContent(serviceID string) ([]string, error) {
+	errorLogPath, err := a.serviceManager.GetServiceErrorLogPath(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if errorLogPath == "" {
+		return nil, fmt.Errorf("service %s writes its error output to the regular log", serviceID)
+	}
+	return a.readAllLines(errorLogPath, a.logLineDecoder(serviceID))
+}
 
-    var lines []string
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        lines = append(lines, scanner.Text())
-    }
-    return lines, scanner.Err()
+// TestLogPathWritable checks that a log file could be written at path.
+// It returns the resolved absolute path, and an error describing why the location is unusable.
+func (a *App) TestLogPathWritable(path string) (bool, string, error) {
+	if strings.TrimSpace(path) == "" {
+		return false, "", fmt.Errorf("log path is empty")
+	}
+
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return false, "", err
+	}
+
+	dir := filepath.Dir(resolved)
+	if info, err := os.Stat(resolved); err == nil && info.IsDir() {
+		dir = resolved
+	}
+
+	if err := ensureWritableDir(dir); err != nil {
+		return false, resolved, err
+	}
+
+	return true, resolved, nil
 }
 
-func (a *App) tailLogFile(ctx context.Context, serviceID, logPath string) {
+// readAllLines is a helper that reads a file and returns its lines passed through decode.
+func (a *App) readAllLines(path string, decode func(string) string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, decode(scanner.Text()))
+	}
+	return lines, scanner.Err()
+}
+
+func (a *App) tailLogFile(ctx context.Context, serviceID, logPath string, lastN int) {
     // Wait for file to exist (up to 10 seconds)
     for range 20 {
         if _, err := os.Stat(logPath); err == nil {
             break
         }
-        time.Sleep(500 * time.Millisecond)
+        if !sleepContext(ctx, 500*time.Millisecond) {
+            return
+        }
     }
 
     file, err := os.Open(logPath)
@@ -233,52 +693,114 @@ func (a *App) tailLogFile(ctx context.Context, serviceID, logPath string) {
         runtime.LogErrorf(a.ctx, "Cannot open log file for %s: %v", serviceID, err)
         return
     }
-    defer file.Close()
+    defer func() {
+        file.Close()
+    }()
 
     // Seek to the end – we only want new lines from now on.
-    if _, err := file.Seek(0, io.SeekEnd); err != nil {
+    end, err := file.Seek(0, io.SeekEnd)
+    if err != nil {
         runtime.LogErrorf(a.ctx, "Seek error for %s: %v", serviceID, err)
         return
     }
 
+    decode := a.logLineDecoder(serviceID)
+
+    // Seed the frontend with the most recent existing lines
+    if lastN > 0 {
+        lines, err := readLastLines(file, end, lastN)
+        if err != nil {
+            runtime.LogErrorf(a.ctx, "Cannot read recent lines for %s: %v", serviceID, err)
+        }
+        for _, line := range lines {
+            a.emitLogLine(serviceID, decode(line))
+        }
+    }
+
     reader := bufio.NewReader(file)
     lineBuf := make([]byte, 0)
 
-    for {
-        select {
-        case <-ctx.Done():
-            return
-        default:
-            line, isPrefix, err := reader.ReadLine()
-            if err != nil {
-                if err != io.EOF {
-                    runtime.LogErrorf(a.ctx, "Read error for %s: %v", serviceID, err)
-                }
-                time.Sleep(500 * time.Millisecond)
+    for ctx.Err() == nil {
+        line, isPrefix, err := reader.ReadLine()
+        if err != nil {
+            if err != io.EOF {
+                runtime.LogErrorf(a.ctx, "Read error for %s: %v", serviceID, err)
+            } else if rotated, ok := reopenIfRotated(file, logPath); ok {
+                // The log was rotated or truncated, continue from the start of the current file
+                file = rotated
+                reader.Reset(file)
+                lineBuf = lineBuf[:0]
                 continue
             }
-
-            lineBuf = append(lineBuf, line...)
-            if !isPrefix {
-                runtime.EventsEmit(a.ctx, "service-log-line", map[string]interface{}{
-                    "serviceId": serviceID,
-                    "line":      string(lineBuf),
-                })
-                lineBuf = lineBuf[:0]
+            if !sleepContext(ctx, 500*time.Millisecond) {
+                return
             }
+            continue
+        }
+
+        lineBuf = append(lineBuf, line...)
+        if !isPrefix {
+            a.emitLogLine(serviceID, decode(string(lineBuf)))
+            lineBuf = lineBuf[:0]
         }
     }
 }
 
+// reopenIfRotated checks whether the log at path was replaced or truncated since file was opened.
+// It returns the file to continue reading from the start, which is a new handle if path now
+// points to a different file, and false if nothing changed.
+func reopenIfRotated(file *os.File, path string) (*os.File, bool) {
+	current, err := file.Stat()
+	if err != nil {
+		return file, false
+	}
+	latest, err := os.Stat(path)
+	if err != nil {
+		return file, false
+	}
+
+	if !os.SameFile(current, latest) {
+		reopened, err := os.Open(path)
+		if err != nil {
+			return file, false
+		}
+		file.Close()
+		return reopened, true
+	}
+
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil || latest.Size() >= offset {
+		return file, false
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return file, false
+	}
+	return file, true
+}
+
+// sleepContext waits for d unless ctx is cancelled first; it reports whether the full wait elapsed
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 // StopMonitoringLog stops tailing the service's log file.
 func (a *App) StopMonitoringService(serviceID string) {
 	a.logTailersLock.Lock()
 	defer a.logTailersLock.Unlock()
-    if info, exists := a.logTailers[serviceID]; exists {
-        info.cancel()
-        <-info.done // Wait for tailer to finish
-        delete(a.logTailers, serviceID)
-    }
+	if info, exists := a.logTailers[serviceID]; exists {
+		info.cancel()
+		<-info.done // Wait for tailer to finish
+		delete(a.logTailers, serviceID)
+		a.saveMonitoredServices()
+	}
 }
 
 // SelectFile opens a file selection dialog
@@ -487,48 +1009,6 @@ func (a *App) GetAutoStartStatus() bool {
 	return err == nil
 }
 
-// RestartAsAdmin restarts the application with administrator privileges
-func (a *App) RestartAsAdmin() error {
-	exe, err := os.Executable()
-	if err != nil {
-		return err
-	}
-
-	cwd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-
-	args := strings.Join(os.Args[1:], " ")
-
-	verbPtr, err := syscall.UTF16PtrFromString("runas")
-	if err != nil {
-		return err
-	}
-	exePtr, err := syscall.UTF16PtrFromString(exe)
-	if err != nil {
-		return err
-	}
-	cwdPtr, err := syscall.UTF16PtrFromString(cwd)
-	if err != nil {
-		return err
-	}
-	argPtr, err := syscall.UTF16PtrFromString(args)
-	if err != nil {
-		return err
-	}
-
-	var showCmd int32 = 1
-
-	err = windows.ShellExecute(0, verbPtr, exePtr, argPtr, cwdPtr, showCmd)
-	if err != nil {
-		return err
-	}
-
-	os.Exit(0)
-	return nil
-}
-
 func (a *App) ShowWindow() {
 	runtime.WindowShow(a.ctx)
 	runtime.WindowUnminimise(a.ctx)
@@ -546,6 +1026,16 @@ func (a *App) SetServiceAutoStart(serviceID string, enabled bool) error {
 	return a.serviceManager.SetServiceAutoStart(serviceID, enabled)
 }
 
+// SetServiceStartType sets the start type of a service: "auto", "delayed", "manual" or "disabled"
+func (a *App) SetServiceStartType(serviceID string, startType string) error {
+	return a.serviceManager.SetServiceStartType(serviceID, startType)
+}
+
+// SetServiceDescription sets the description shown for a service in services.msc
+func (a *App) SetServiceDescription(serviceID, description string) error {
+	return a.serviceManager.SetServiceDescription(serviceID, description)
+}
+
 // GetServiceAutoStart retrieves the auto-start status of a service
 func (a *App) GetServiceAutoStart(serviceID string) bool {
 	return a.serviceManager.GetServiceAutoStart(serviceID)
@@ -556,11 +1046,86 @@ func (a *App) AddSystemEnvironmentVariable(varName, varValue string) error {
 	return a.environmentManager.AddSystemEnvironmentVariable(varName, varValue)
 }
 
+// SetSystemEnvironmentVariable replaces the value of a system environment variable
+func (a *App) SetSystemEnvironmentVariable(varName, varValue string) error {
+	return a.environmentManager.SetSystemEnvironmentVariable(varName, varValue)
+}
+
 // AddPathVariable adds a PATH environment variable
 func (a *App) AddPathVariable(pathValue string) error {
 	return a.environmentManager.AddPathVariable(pathValue)
 }
 
+// RemoveSystemEnvironmentVariable removes a system environment variable
+func (a *App) RemoveSystemEnvironmentVariable(varName string) error {
+	return a.environmentManager.RemoveSystemEnvironmentVariable(varName)
+}
+
+// RemovePathEntry removes a directory from the PATH environment variable
+func (a *App) RemovePathEntry(pathValue string) error {
+	return a.environmentManager.RemovePathEntry(pathValue)
+}
+
+// CleanPath removes duplicate directories, and with removeDead missing ones, from the system PATH and returns what was removed
+func (a *App) CleanPath(removeDead bool) ([]string, error) {
+	return a.environmentManager.CleanPath(removeDead)
+}
+
+// CleanUserPath removes duplicate and optionally missing directories from the current user's PATH
+func (a *App) CleanUserPath(removeDead bool) ([]string, error) {
+	return a.environmentManager.CleanUserPath(removeDead)
+}
+
+// BackupEnvironment saves all system environment variables to a file in the config directory and returns its path
+func (a *App) BackupEnvironment() (string, error) {
+	return a.environmentManager.BackupEnvironment()
+}
+
+// RestoreEnvironment rewrites the system environment variables from a backup file
+func (a *App) RestoreEnvironment(backupPath string) error {
+	return a.environmentManager.RestoreEnvironment(backupPath)
+}
+
+// AddUserEnvironmentVariable adds an environment variable for the current user (no administrator rights needed)
+func (a *App) AddUserEnvironmentVariable(varName, varValue string) error {
+	return a.environmentManager.AddUserEnvironmentVariable(varName, varValue)
+}
+
+// SetUserEnvironmentVariable replaces the value of an environment variable of the current user
+func (a *App) SetUserEnvironmentVariable(varName, varValue string) error {
+	return a.environmentManager.SetUserEnvironmentVariable(varName, varValue)
+}
+
+// GetUserEnvironmentVariable returns the value of an environment variable of the current user
+func (a *App) GetUserEnvironmentVariable(varName string) (string, error) {
+	return a.environmentManager.GetUserEnvironmentVariable(varName)
+}
+
+// RemoveUserEnvironmentVariable removes an environment variable of the current user
+func (a *App) RemoveUserEnvironmentVariable(varName string) error {
+	return a.environmentManager.RemoveUserEnvironmentVariable(varName)
+}
+
+// AddUserPathVariable adds a directory to the current user's PATH
+func (a *App) AddUserPathVariable(pathValue string) error {
+	return a.environmentManager.AddUserPathVariable(pathValue)
+}
+
+// RemoveUserPathEntry removes a directory from the current user's PATH
+func (a *App) RemoveUserPathEntry(pathValue string) error {
+	return a.environmentManager.RemoveUserPathEntry(pathValue)
+}
+
+// AddPathSegment adds a directory to the PATH of a scope ("user" or "system") through a variable of its own
+func (a *App) AddPathSegment(scope, segmentName, pathValue string) error {
+	return a.environmentManager.AddPathSegment(scope, segmentName, pathValue)
+}
+
+// ListEnvironmentVariables lists the environment variables of a scope ("user" or "system")
+func (a *App) ListEnvironmentVariables(scope string) (map[string]string, error) {
+	return a.environmentManager.ListEnvironmentVariables(scope)
+}
+
 // OpenSystemEnvironmentSettings opens the system environment variables settings window
 func (a *App) OpenSystemEnvironmentSettings() error {
 	return a.environmentManager.OpenSystemEnvironmentSettings()
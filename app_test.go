@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// newTestApp returns an App around a test manager, without the Wails runtime
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	return &App{
+		serviceManager: newTestManager(t, newFakeConnector()),
+		logTailers:     make(map[string]*tailerInfo),
+	}
+}
+
+func TestLogMonitoringDoesNotLeakGoroutines(t *testing.T) {
+	app := newTestApp(t)
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logPath, nil, 0644); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	addTestService(app.serviceManager, "WSM_app", "running").LogPath = logPath
+
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		if err := app.StartMonitoringService("WSM_app"); err != nil {
+			t.Fatalf("StartMonitoringService: %v", err)
+		}
+		app.StopMonitoringService("WSM_app")
+	}
+
+	// Give exiting goroutines a moment to be accounted for
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Errorf("%d goroutines after 100 monitoring cycles, want at most %d", n, baseline)
+	}
+	if len(app.logTailers) != 0 {
+		t.Errorf("%d tailers still registered", len(app.logTailers))
+	}
+}
+
+func TestLogMonitoringRestartReplacesTailer(t *testing.T) {
+	app := newTestApp(t)
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logPath, nil, 0644); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	addTestService(app.serviceManager, "WSM_app", "running").LogPath = logPath
+
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		if err := app.StartMonitoringService("WSM_app"); err != nil {
+			t.Fatalf("StartMonitoringService: %v", err)
+		}
+	}
+	if n := runtime.NumGoroutine(); n > baseline+1 {
+		t.Errorf("%d goroutines with one service monitored, want at most %d", n, baseline+1)
+	}
+	app.StopMonitoringService("WSM_app")
+}
+
+func TestClearServiceLogResumesMonitoringWhenClearingFails(t *testing.T) {
+	app := newTestApp(t)
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logPath, nil, 0644); err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	addTestService(app.serviceManager, "WSM_app", "running").LogPath = logPath
+
+	if err := app.StartMonitoringService("WSM_app"); err != nil {
+		t.Fatalf("StartMonitoringService: %v", err)
+	}
+	defer app.StopMonitoringService("WSM_app")
+
+	// A read-only file cannot be truncated
+	if err := os.Chmod(logPath, 0444); err != nil {
+		t.Fatalf("failed to make log read-only: %v", err)
+	}
+	defer os.Chmod(logPath, 0644)
+
+	if err := app.ClearServiceLog("WSM_app"); err == nil {
+		t.Fatalf("ClearServiceLog succeeded on a read-only log")
+	}
+
+	app.logTailersLock.Lock()
+	_, monitoring := app.logTailers["WSM_app"]
+	app.logTailersLock.Unlock()
+	if !monitoring {
+		t.Errorf("log is no longer monitored after clearing it failed")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// splitArgs splits an argument string the way Windows programs parse their command line,
+// so quoted arguments like "C:\Program Files\x" stay in one piece
+func splitArgs(args string) ([]string, error) {
+	if strings.TrimSpace(args) == "" {
+		return nil, nil
+	}
+	// The first token of a command line is parsed as the program name, which follows
+	// different quoting rules, so parse the arguments behind a placeholder program
+	parsed, err := windows.DecomposeCommandLine("wsm " + args)
+	if err != nil {
+		return nil, err
+	}
+	return parsed[1:], nil
+}
+
+// joinArgs quotes arguments so splitArgs returns them unchanged
+func joinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = windows.EscapeArg(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// normalizeArgs makes Args and ArgsList of config describe the same arguments.
+// ArgsList wins when both are set; a flat Args string from older clients is parsed into ArgsList.
+func normalizeArgs(config *ServiceConfig) error {
+	if len(config.ArgsList) > 0 {
+		config.Args = joinArgs(config.ArgsList)
+		return nil
+	}
+
+	argsList, err := splitArgs(config.Args)
+	if err != nil {
+		return fmt.Errorf("invalid arguments %q: %v", config.Args, err)
+	}
+	config.ArgsList = argsList
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Audit log rotation limits
+const (
+	auditMaxSizeMB  = 1
+	auditMaxBackups = 3
+)
+
+// defaultAuditLimit is how many entries GetAuditLog returns when no limit is given
+const defaultAuditLimit = 100
+
+// auditMutex serializes writes to the audit log
+var auditMutex sync.Mutex
+
+// AuditEntry is one recorded service operation
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	ServiceID string    `json:"serviceId"`
+	Action    string    `json:"action"` // "create", "edit", "start", "stop", "restart", "reload", "force-stop" or "delete"
+	Result    string    `json:"result"` // "success" or "failure"
+	Error     string    `json:"error,omitempty"`
+}
+
+// getAuditLogPath returns the path of the audit log
+func getAuditLogPath() (string, error) {
+	return configFilePath("audit.jsonl")
+}
+
+// recordAudit appends an operation and its outcome to the audit log.
+// Failing to write the audit log never fails the operation itself.
+func recordAudit(serviceID, action string, opErr error) {
+	entry := AuditEntry{
+		Time:      time.Now(),
+		ServiceID: serviceID,
+		Action:    action,
+		Result:    "success",
+	}
+	if opErr != nil {
+		entry.Result = "failure"
+		entry.Error = opErr.Error()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	path, err := getAuditLogPath()
+	if err != nil {
+		fmt.Printf("Warning: failed to write audit log: %v\n", err)
+		return
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Printf("Warning: failed to write audit log: %v\n", err)
+		return
+	}
+
+	writer, err := openRotatingWriter(path, auditMaxSizeMB, auditMaxBackups)
+	if err != nil {
+		fmt.Printf("Warning: failed to write audit log: %v\n", err)
+		return
+	}
+	defer writer.Close()
+
+	if _, err := writer.Write(append(line, '\n')); err != nil {
+		fmt.Printf("Warning: failed to write audit log: %v\n", err)
+	}
+}
+
+// GetAuditLog returns up to limit audit entries for serviceID (all services when empty), newest first
+func (a *App) GetAuditLog(serviceID string, limit int) ([]AuditEntry, error) {
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	path, err := getAuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	// The current file holds the newest entries, rotated files get older with their number
+	rw := &rotatingWriter{path: path}
+	files := []string{path}
+	for n := 1; n <= auditMaxBackups; n++ {
+		files = append(files, rw.backupPath(n))
+	}
+
+	entries := make([]AuditEntry, 0)
+	for _, file := range files {
+		fileEntries, err := readAuditFile(file, serviceID)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i := len(fileEntries) - 1; i >= 0; i-- {
+			entries = append(entries, fileEntries[i])
+			if len(entries) == limit {
+				return entries, nil
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// readAuditFile reads the entries of one audit file in file order, skipping unreadable lines
+func readAuditFile(path, serviceID string) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if serviceID != "" && !strings.EqualFold(entry.ServiceID, serviceID) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %v", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// StartAllServices starts every managed service over a single SCM connection, dependencies first.
+// It returns serviceID -> error message, with an empty message for each service that started.
+func (wsm *WindowsServiceManager) StartAllServices() map[string]string {
+	return wsm.allServicesOperation("", false, func(scm scmConnection, serviceID string, service *Service) error {
+		return wsm.startServiceWithSCM(scm, serviceID, service)
+	})
+}
+
+// StopAllServices stops every managed service over a single SCM connection, stopping dependents
+// before the services they depend on. Critical services are not stopped and report an error
+// asking for an individual, confirmed stop.
+func (wsm *WindowsServiceManager) StopAllServices() map[string]string {
+	return wsm.allServicesOperation("stop", true, func(scm scmConnection, serviceID string, service *Service) error {
+		return wsm.stopServiceWithSCM(scm, serviceID, service)
+	})
+}
+
+// PauseAllServices pauses every managed service that is running, dependents first.
+// Services that do not accept pause and continue report an error.
+func (wsm *WindowsServiceManager) PauseAllServices() map[string]string {
+	return wsm.allServicesOperation("", true, func(scm scmConnection, serviceID string, service *Service) error {
+		return wsm.pauseOrContinueWithSCM(scm, serviceID, service, svc.Pause, svc.Paused, "paused")
+	})
+}
+
+// allServicesOperation runs operation for all managed services in dependency order, or in reverse
+// dependency order (dependents first) when dependentsFirst is set. Per-service status events are
+// held back and a single services-updated event is emitted at the end.
+func (wsm *WindowsServiceManager) allServicesOperation(guardedAction string, dependentsFirst bool, operation func(scmConnection, string, *Service) error) map[string]string {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	serviceIDs := wsm.dependencyOrder()
+	if dependentsFirst {
+		for i, j := 0, len(serviceIDs)-1; i < j; i, j = i+1, j-1 {
+			serviceIDs[i], serviceIDs[j] = serviceIDs[j], serviceIDs[i]
+		}
+	}
+
+	wsm.quietStatusEvents = true
+	results := wsm.runServiceOperation(serviceIDs, guardedAction, operation)
+	wsm.quietStatusEvents = false
+
+	wsm.emitServicesUpdated()
+	return results
+}
+
+// dependencyOrder returns the IDs of all managed services with every service after the managed
+// services it depends on. Dependency cycles are broken arbitrarily; the caller holds the lock.
+func (wsm *WindowsServiceManager) dependencyOrder() []string {
+	ids := make([]string, 0, len(wsm.services))
+	byLowerID := make(map[string]string, len(wsm.services))
+	for id := range wsm.services {
+		ids = append(ids, id)
+		byLowerID[strings.ToLower(id)] = id
+	}
+	sort.Strings(ids)
+
+	order := make([]string, 0, len(ids))
+	visited := make(map[string]bool, len(ids))
+
+	var visit func(id string)
+	visit = func(id string) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+
+		for _, dependency := range wsm.services[id].Dependencies {
+			if depID, managed := byLowerID[strings.ToLower(dependency)]; managed {
+				visit(depID)
+			}
+		}
+		order = append(order, id)
+	}
+
+	for _, id := range ids {
+		visit(id)
+	}
+	return order
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -10,6 +11,35 @@ type ServiceStatusCache struct {
 	cache map[string]*CachedServiceStatus
 	mutex sync.RWMutex
 	ttl   time.Duration
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	sets          atomic.Int64
+	evictions     atomic.Int64 // expired entries removed by CleanExpired
+	invalidations atomic.Int64
+	sharedQueries atomic.Int64
+
+	// inflight holds the SCM queries currently running, so concurrent misses for the same service share one
+	inflightMutex sync.Mutex
+	inflight      map[string]*statusQuery
+}
+
+// statusQuery is an SCM status query that other callers can wait for
+type statusQuery struct {
+	done   chan struct{}
+	status string
+	pid    int
+}
+
+// CacheStats describes how well the status cache is doing
+type CacheStats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"` // lookups of missing or expired entries
+	Sets          int64 `json:"sets"`
+	Evictions     int64 `json:"evictions"`
+	Invalidations int64 `json:"invalidations"` // entries dropped because they no longer matched SCM
+	SharedQueries int64 `json:"sharedQueries"` // misses answered by joining a query already in flight instead of opening SCM again
+	Entries       int   `json:"entries"`
 }
 
 // CachedServiceStatus represents a cached service status
@@ -22,8 +52,9 @@ type CachedServiceStatus struct {
 // NewServiceStatusCache creates a new service status cache
 func NewServiceStatusCache() *ServiceStatusCache {
 	return &ServiceStatusCache{
-		cache: make(map[string]*CachedServiceStatus),
-		ttl:   5 * time.Second, // cache TTL: 5 seconds
+		cache:    make(map[string]*CachedServiceStatus),
+		ttl:      5 * time.Second, // cache TTL: 5 seconds
+		inflight: make(map[string]*statusQuery),
 	}
 }
 
@@ -34,13 +65,16 @@ func (cache *ServiceStatusCache) Get(serviceName string) (*CachedServiceStatus,
 
 	status, exists := cache.cache[serviceName]
 	if !exists {
+		cache.misses.Add(1)
 		return nil, false
 	}
 
 	if time.Since(status.Timestamp) > cache.ttl {
+		cache.misses.Add(1)
 		return nil, false
 	}
 
+	cache.hits.Add(1)
 	return status, true
 }
 
@@ -54,6 +88,37 @@ func (cache *ServiceStatusCache) Set(serviceName string, status string, pid int)
 		PID:       pid,
 		Timestamp: time.Now(),
 	}
+	cache.sets.Add(1)
+}
+
+// Load returns the cached status of a service, running query on a miss and caching its result.
+// Concurrent misses for the same service wait for the first query instead of each opening the service
+// in SCM, so a GetServices refresh racing the status watcher or a details view costs one query per service.
+func (cache *ServiceStatusCache) Load(serviceName string, query func() (string, int)) (string, int) {
+	if status, found := cache.Get(serviceName); found {
+		return status.Status, status.PID
+	}
+
+	cache.inflightMutex.Lock()
+	if running, exists := cache.inflight[serviceName]; exists {
+		cache.inflightMutex.Unlock()
+		cache.sharedQueries.Add(1)
+		<-running.done
+		return running.status, running.pid
+	}
+	current := &statusQuery{done: make(chan struct{})}
+	cache.inflight[serviceName] = current
+	cache.inflightMutex.Unlock()
+
+	current.status, current.pid = query()
+	cache.Set(serviceName, current.status, current.pid)
+
+	cache.inflightMutex.Lock()
+	delete(cache.inflight, serviceName)
+	cache.inflightMutex.Unlock()
+	close(current.done)
+
+	return current.status, current.pid
 }
 
 // Remove deletes a service status from the cache
@@ -64,12 +129,46 @@ func (cache *ServiceStatusCache) Remove(serviceName string) {
 	delete(cache.cache, serviceName)
 }
 
-// Clear empties the entire cache
+// Invalidate drops a status that is known or suspected to be out of date,
+// so the next lookup queries SCM again
+func (cache *ServiceStatusCache) Invalidate(serviceName string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if _, exists := cache.cache[serviceName]; exists {
+		delete(cache.cache, serviceName)
+		cache.invalidations.Add(1)
+	}
+}
+
+// Clear empties the entire cache and resets its statistics
 func (cache *ServiceStatusCache) Clear() {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
 	cache.cache = make(map[string]*CachedServiceStatus)
+	cache.hits.Store(0)
+	cache.misses.Store(0)
+	cache.sets.Store(0)
+	cache.evictions.Store(0)
+	cache.invalidations.Store(0)
+	cache.sharedQueries.Store(0)
+}
+
+// Stats returns the cache statistics collected since creation or the last Clear
+func (cache *ServiceStatusCache) Stats() CacheStats {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return CacheStats{
+		Hits:          cache.hits.Load(),
+		Misses:        cache.misses.Load(),
+		Sets:          cache.sets.Load(),
+		Evictions:     cache.evictions.Load(),
+		Invalidations: cache.invalidations.Load(),
+		SharedQueries: cache.sharedQueries.Load(),
+		Entries:       len(cache.cache),
+	}
 }
 
 // CleanExpired removes expired cache entries
@@ -81,6 +180,7 @@ func (cache *ServiceStatusCache) CleanExpired() {
 	for serviceName, status := range cache.cache {
 		if now.Sub(status.Timestamp) > cache.ttl {
 			delete(cache.cache, serviceName)
+			cache.evictions.Add(1)
 		}
 	}
 }
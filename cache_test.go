@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestStatusCacheInvalidateDropsStaleEntry(t *testing.T) {
+	cache := NewServiceStatusCache()
+	cache.Set("WSM_app", "running", fakeServicePID)
+
+	cache.Invalidate("WSM_app")
+
+	if _, found := cache.Get("WSM_app"); found {
+		t.Errorf("invalidated entry is still served")
+	}
+	status, pid := cache.Load("WSM_app", func() (string, int) { return "stopped", 0 })
+	if status != "stopped" || pid != 0 {
+		t.Errorf("Load after invalidation = %s/%d, want the fresh query result", status, pid)
+	}
+}
+
+func TestStatusCacheRefreshedAfterStartAndStop(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	addTestService(wsm, "WSM_app", "stopped")
+	wsm.statusCache.Set("WSM_app", "stopped", 0)
+
+	if err := wsm.StartService("WSM_app"); err != nil {
+		t.Fatalf("StartService: %v", err)
+	}
+	if cached, found := wsm.statusCache.Get("WSM_app"); !found || cached.Status != "running" {
+		t.Errorf("cache after start = %+v, want running", cached)
+	}
+
+	if err := wsm.stopService("WSM_app"); err != nil {
+		t.Fatalf("stopService: %v", err)
+	}
+	if cached, found := wsm.statusCache.Get("WSM_app"); !found || cached.Status != "stopped" {
+		t.Errorf("cache after stop = %+v, want stopped", cached)
+	}
+}
+
+func TestStatusCacheInvalidatedWhenStopFails(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	fake.controlErr = errors.New("control refused")
+	wsm := newTestManager(t, newFakeConnector(fake))
+	addTestService(wsm, "WSM_app", "running")
+	wsm.statusCache.Set("WSM_app", "running", fakeServicePID)
+
+	if err := wsm.stopService("WSM_app"); err == nil {
+		t.Fatalf("stopService succeeded although the stop control failed")
+	}
+	if _, found := wsm.statusCache.Get("WSM_app"); found {
+		t.Errorf("cache still holds the status of a service whose stop failed")
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/sys/windows"
+)
+
+// attachParentProcess is the AttachConsole argument for the console of the parent process
+const attachParentProcess = ^uint32(0)
+
+// cliUsage describes the headless commands accepted on the command line
+const cliUsage = `Usage:
+  --list            list managed services and their status
+  --start <name>    start a managed service
+  --stop <name>     stop a managed service
+  --status <name>   print the status of a managed service
+
+<name> is the service name or its display name.`
+
+// runCLI runs a headless command from args (without the program name).
+// It reports false when args contain no recognized command, so the GUI should start.
+func runCLI(args []string) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+
+	command := args[0]
+	switch command {
+	case "--list", "--start", "--stop", "--status":
+	case "--help", "-h", "/?":
+		attachParentConsole()
+		fmt.Println(cliUsage)
+		return true, 0
+	default:
+		return false, 0
+	}
+
+	attachParentConsole()
+
+	var name string
+	if command != "--list" {
+		if len(args) < 2 || args[1] == "" {
+			fmt.Fprintf(os.Stderr, "%s requires a service name\n\n%s\n", command, cliUsage)
+			return true, 2
+		}
+		name = args[1]
+	}
+
+	migrateLegacyConfig()
+	manager := NewWindowsServiceManager()
+	if err := manager.loadServices(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	var err error
+	switch command {
+	case "--list":
+		err = cliList(manager)
+	case "--start":
+		err = cliControl(manager, name, "start")
+	case "--stop":
+		err = cliControl(manager, name, "stop")
+	case "--status":
+		err = cliStatus(manager, name)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return true, 1
+	}
+	return true, 0
+}
+
+// cliList prints all managed services with their live status
+func cliList(manager *WindowsServiceManager) error {
+	services, err := manager.GetServices()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tDISPLAY NAME\tSTATUS\tPID")
+	for _, service := range services {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%d\n", service.ID, service.Name, service.Status, service.PID)
+	}
+	return writer.Flush()
+}
+
+// cliStatus prints the live status of one managed service
+func cliStatus(manager *WindowsServiceManager, name string) error {
+	service, err := cliFindService(manager, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (%s): %s", service.ID, service.Name, service.Status)
+	if service.PID != 0 {
+		fmt.Printf(", PID %d", service.PID)
+	}
+	fmt.Println()
+	return nil
+}
+
+// cliControl starts or stops one managed service
+func cliControl(manager *WindowsServiceManager, name, action string) error {
+	service, err := cliFindService(manager, name)
+	if err != nil {
+		return err
+	}
+
+	// Running the command is the confirmation, so critical services are not guarded here
+	if action == "start" {
+		err = manager.StartService(service.ID)
+	} else {
+		err = manager.stopService(service.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %s ok\n", service.ID, action)
+	return nil
+}
+
+// cliFindService looks up a managed service by service name, then by display name
+func cliFindService(manager *WindowsServiceManager, name string) (*Service, error) {
+	services, err := manager.GetServices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, service := range services {
+		if strings.EqualFold(service.ID, name) {
+			return service, nil
+		}
+	}
+	for _, service := range services {
+		if strings.EqualFold(service.Name, name) {
+			return service, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, name)
+}
+
+// attachParentConsole connects stdout and stderr to the console that launched the app.
+// The GUI build has no console of its own, so output would otherwise be lost.
+func attachParentConsole() {
+	if ret, _, _ := procAttachConsole.Call(uintptr(attachParentProcess)); ret == 0 {
+		return
+	}
+
+	console, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	os.Stdout = console
+	os.Stderr = console
+	windows.SetStdHandle(windows.STD_OUTPUT_HANDLE, windows.Handle(console.Fd()))
+	windows.SetStdHandle(windows.STD_ERROR_HANDLE, windows.Handle(console.Fd()))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configDirName is the folder under the user config dir that holds all app state
+const configDirName = "Windows Service Manager"
+
+// legacyConfigDirNames are folders used by earlier versions, migrated on startup
+var legacyConfigDirNames = []string{"Windows Service Manager.exe", "Windows-Services-Manager"}
+
+// configFileNames are the files kept in the config dir
+var configFileNames = []string{"data.json", "theme.json", "settings.json", "monitored.json", "audit.jsonl"}
+
+// configDirOverrideFile, kept in the default config dir, points to a custom config dir
+const configDirOverrideFile = "configdir.txt"
+
+// defaultConfigDir returns the config dir used unless SetConfigDir chose another one
+func defaultConfigDir() (string, error) {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userConfigDir, configDirName), nil
+}
+
+// getConfigDir returns the directory holding data, theme, settings and monitoring state
+func getConfigDir() (string, error) {
+	dir, err := defaultConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, configDirOverrideFile)); err == nil {
+		if override := strings.TrimSpace(string(data)); override != "" {
+			return override, nil
+		}
+	}
+	return dir, nil
+}
+
+// configFilePath returns the path of a file in the config dir
+func configFilePath(name string) (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// migrateLegacyConfig moves config files left in folders of earlier versions into the config dir.
+// Files that already exist in the config dir are not overwritten.
+func migrateLegacyConfig() {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return
+	}
+	dir, err := getConfigDir()
+	if err != nil {
+		return
+	}
+
+	for _, legacyName := range legacyConfigDirNames {
+		legacyDir := filepath.Join(userConfigDir, legacyName)
+		if strings.EqualFold(legacyDir, dir) {
+			continue
+		}
+		if err := moveConfigFiles(legacyDir, dir); err != nil {
+			fmt.Printf("Warning: failed to migrate config from %s: %v\n", legacyDir, err)
+		}
+	}
+}
+
+// moveConfigFiles moves the known config files from one directory to another, skipping
+// files that are missing in from or already present in to
+func moveConfigFiles(from, to string) error {
+	for _, name := range configFileNames {
+		source := filepath.Join(from, name)
+		target := filepath.Join(to, name)
+
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+
+		if err := os.MkdirAll(to, 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(source, target); err != nil {
+			// Rename fails across volumes, so fall back to copying
+			if err := copyFile(source, target); err != nil {
+				return err
+			}
+			os.Remove(source)
+		}
+	}
+	return nil
+}
+
+// GetConfigDir returns the directory where the app keeps its state
+func (a *App) GetConfigDir() string {
+	dir, err := getConfigDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// SetConfigDir moves the app state to dir, for example next to the executable for a portable
+// install. An empty dir goes back to the default location.
+func (a *App) SetConfigDir(dir string) error {
+	defaultDir, err := defaultConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get default config dir: %v", err)
+	}
+
+	target := defaultDir
+	if dir != "" {
+		if target, err = resolvePath(dir); err != nil {
+			return err
+		}
+	}
+	if err := ensureWritableDir(target); err != nil {
+		return err
+	}
+
+	current, err := getConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config dir: %v", err)
+	}
+	if strings.EqualFold(current, target) {
+		return nil
+	}
+
+	if err := moveConfigFiles(current, target); err != nil {
+		return fmt.Errorf("failed to move config files: %v", err)
+	}
+
+	if err := os.MkdirAll(defaultDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config dir: %v", err)
+	}
+	overridePath := filepath.Join(defaultDir, configDirOverrideFile)
+	if strings.EqualFold(target, defaultDir) {
+		if err := os.Remove(overridePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset config dir: %v", err)
+		}
+	} else if err := os.WriteFile(overridePath, []byte(target), 0644); err != nil {
+		return fmt.Errorf("failed to save config dir: %v", err)
+	}
+
+	// Rewrite the moved data file so it reflects the current state
+	a.serviceManager.SetDataFile(filepath.Join(target, "data.json"))
+	return nil
+}
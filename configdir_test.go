@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	appData := t.TempDir()
+	t.Setenv("AppData", appData)
+
+	legacyDir := filepath.Join(appData, "Windows Service Manager.exe")
+	configDir := filepath.Join(appData, configDirName)
+	for _, dir := range []string{legacyDir, configDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	writeFile(filepath.Join(legacyDir, "data.json"), "legacy data")
+	writeFile(filepath.Join(legacyDir, "settings.json"), "legacy settings")
+	writeFile(filepath.Join(legacyDir, "unrelated.txt"), "not a config file")
+	writeFile(filepath.Join(configDir, "settings.json"), "current settings")
+
+	migrateLegacyConfig()
+
+	readFile := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		return string(data)
+	}
+	if got := readFile(filepath.Join(configDir, "data.json")); got != "legacy data" {
+		t.Errorf("data.json = %q, want it moved from the legacy folder", got)
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, "data.json")); !os.IsNotExist(err) {
+		t.Errorf("data.json is still in the legacy folder")
+	}
+	if got := readFile(filepath.Join(configDir, "settings.json")); got != "current settings" {
+		t.Errorf("settings.json = %q, an existing file must not be overwritten", got)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "unrelated.txt")); !os.IsNotExist(err) {
+		t.Errorf("a file that is not a config file was migrated")
+	}
+
+	// A second run has nothing left to do
+	migrateLegacyConfig()
+	if got := readFile(filepath.Join(configDir, "data.json")); got != "legacy data" {
+		t.Errorf("data.json = %q after a second migration", got)
+	}
+}
+
+func TestDefaultLogDirSharesConfigDirName(t *testing.T) {
+	programData := t.TempDir()
+	t.Setenv("ProgramData", programData)
+
+	if got, want := defaultLogDir(), filepath.Join(programData, "Windows Service Manager", "logs"); got != want {
+		t.Errorf("defaultLogDir() = %s, want %s", got, want)
+	}
+}
+
+func TestIsDefaultLogPathAcceptsLegacyDefault(t *testing.T) {
+	programData := t.TempDir()
+	t.Setenv("ProgramData", programData)
+	wsm := newTestManager(t, newFakeConnector())
+
+	current := filepath.Join(programData, "Windows Service Manager", "logs", "WSM_app", "WSM_app.log")
+	legacy := filepath.Join(programData, "Windows Service Manager.exe", "logs", "WSM_app", "WSM_app.log")
+	custom := filepath.Join(programData, "elsewhere", "WSM_app.log")
+
+	if !wsm.isDefaultLogPath("WSM_app", current) {
+		t.Errorf("current default log path not recognized")
+	}
+	if !wsm.isDefaultLogPath("WSM_app", legacy) {
+		t.Errorf("legacy default log path not recognized")
+	}
+	if wsm.isDefaultLogPath("WSM_app", custom) {
+		t.Errorf("custom log path taken for the default")
+	}
+}
+
+func TestManagerWithoutUserConfigDir(t *testing.T) {
+	t.Setenv("AppData", "")
+
+	wsm := NewWindowsServiceManager()
+	if wsm == nil {
+		t.Fatalf("NewWindowsServiceManager returned nil")
+	}
+	if wsm.dataFile != fallbackDataPath() {
+		t.Errorf("data file = %s, want the fallback %s", wsm.dataFile, fallbackDataPath())
+	}
+
+	// Keep the test from writing next to the test binary
+	wsm.dataFile = filepath.Join(t.TempDir(), "services_data.json")
+	wsm.scm = newFakeConnector(newFakeService("Ext", svc.Running))
+	if err := wsm.AdoptService("Ext"); err != nil {
+		t.Fatalf("AdoptService: %v", err)
+	}
+	if _, err := os.Stat(wsm.dataFile); err != nil {
+		t.Errorf("services were not saved to the fallback data file: %v", err)
+	}
+}
+
+func TestManagerWithUnwritableConfigDir(t *testing.T) {
+	// A file where the config dir's parent should be makes the config dir impossible to create
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("failed to create blocker: %v", err)
+	}
+	t.Setenv("AppData", blocker)
+
+	migrateLegacyConfig()
+	if settings := loadSettings(); settings != defaultSettings() {
+		t.Errorf("loadSettings = %+v, want the defaults", settings)
+	}
+	if err := saveSettings(defaultSettings()); err == nil {
+		t.Errorf("saveSettings succeeded in a config dir that cannot exist")
+	}
+
+	wsm := NewWindowsServiceManager()
+	if wsm == nil {
+		t.Fatalf("NewWindowsServiceManager returned nil")
+	}
+	if err := wsm.loadServices(); err != nil {
+		t.Errorf("loadServices: %v", err)
+	}
+
+	// Saving fails with a warning, the services stay usable in memory
+	wsm.scm = newFakeConnector(newFakeService("Ext", svc.Running))
+	if err := wsm.AdoptService("Ext"); err != nil {
+		t.Fatalf("AdoptService: %v", err)
+	}
+	services, err := wsm.QueryServices(ServiceFilter{})
+	if err != nil || len(services) != 1 {
+		t.Errorf("QueryServices = %d services, %v, want the adopted service", len(services), err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// confirmationTTL is how long a confirmation token stays valid
+const confirmationTTL = 60 * time.Second
+
+// pendingAction is an operation waiting for the user to confirm it
+type pendingAction struct {
+	serviceID string
+	action    string
+	expires   time.Time
+}
+
+// confirmationStore hands out short-lived single-use tokens for guarded operations
+type confirmationStore struct {
+	mutex   sync.Mutex
+	pending map[string]pendingAction
+}
+
+// newConfirmationStore creates an empty confirmation store
+func newConfirmationStore() *confirmationStore {
+	return &confirmationStore{
+		pending: make(map[string]pendingAction),
+	}
+}
+
+// issue creates a token that authorizes action on serviceID once
+func (store *confirmationStore) issue(serviceID, action string) string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	for t, p := range store.pending {
+		if now.After(p.expires) {
+			delete(store.pending, t)
+		}
+	}
+
+	store.pending[token] = pendingAction{
+		serviceID: serviceID,
+		action:    action,
+		expires:   now.Add(confirmationTTL),
+	}
+	return token
+}
+
+// consume returns and invalidates the action bound to token
+func (store *confirmationStore) consume(token string) (pendingAction, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	p, exists := store.pending[token]
+	if !exists {
+		return pendingAction{}, false
+	}
+	delete(store.pending, token)
+
+	if time.Now().After(p.expires) {
+		return pendingAction{}, false
+	}
+	return p, true
+}
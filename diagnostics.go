@@ -0,0 +1,153 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// GroupDiagnosticsSummary is the top-level report of a group diagnostics bundle
+type GroupDiagnosticsSummary struct {
+	Tag         string            `json:"tag"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Total       int               `json:"total"`
+	ByStatus    map[string]int    `json:"byStatus"`
+	Services    map[string]string `json:"services"` // serviceID -> status
+}
+
+// hasTag reports whether a service carries tag (case-insensitive)
+func hasTag(service *Service, tag string) bool {
+	for _, t := range service.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeZipJSON stores a value as an indented JSON file in the archive
+func writeZipJSON(zw *zip.Writer, name string, value interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeServiceDiagnostics adds the configuration, status snapshot and log of one service under dir
+func (a *App) writeServiceDiagnostics(zw *zip.Writer, dir string, service *Service) error {
+	if err := writeZipJSON(zw, dir+"/service.json", service); err != nil {
+		return err
+	}
+
+	if details, err := a.serviceManager.GetServiceDetails(service.ID); err == nil {
+		if err := writeZipJSON(zw, dir+"/details.json", details); err != nil {
+			return err
+		}
+	}
+
+	if imagePath, err := a.serviceManager.GetServiceImagePath(service.ID); err == nil {
+		w, err := zw.Create(dir + "/imagepath.txt")
+		if err != nil {
+			return err
+		}
+		io.WriteString(w, imagePath)
+	}
+
+	logPath, _, err := a.serviceManager.GetServiceLogPath(service.ID)
+	if err != nil {
+		return nil
+	}
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		return nil
+	}
+	defer logFile.Close()
+
+	w, err := zw.Create(dir + "/service.log")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, logFile)
+	return err
+}
+
+// ExportGroupDiagnostics bundles config, status and logs of every service with tag into a zip file.
+// It returns the written path, or an empty string if the dialog was cancelled.
+func (a *App) ExportGroupDiagnostics(tag string) (string, error) {
+	services, err := a.serviceManager.GetServices()
+	if err != nil {
+		return "", err
+	}
+
+	var tagged []*Service
+	for _, service := range services {
+		if hasTag(service, tag) {
+			tagged = append(tagged, service)
+		}
+	}
+	if len(tagged) == 0 {
+		return "", fmt.Errorf("no services are tagged %q", tag)
+	}
+
+	target, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Group Diagnostics",
+		DefaultFilename: fmt.Sprintf("%s_diagnostics_%s.zip", tag, time.Now().Format("20060102-150405")),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "Zip Archives (*.zip)",
+				Pattern:     "*.zip",
+			},
+		},
+	})
+	if err != nil || target == "" {
+		return "", err
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to create diagnostics file: %v", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+
+	summary := GroupDiagnosticsSummary{
+		Tag:         tag,
+		GeneratedAt: time.Now(),
+		Total:       len(tagged),
+		ByStatus:    make(map[string]int),
+		Services:    make(map[string]string),
+	}
+	for _, service := range tagged {
+		summary.ByStatus[service.Status]++
+		summary.Services[service.ID] = service.Status
+
+		if err := a.writeServiceDiagnostics(zw, service.ID, service); err != nil {
+			zw.Close()
+			return "", fmt.Errorf("failed to write diagnostics for %s: %v", service.ID, err)
+		}
+	}
+
+	if err := writeZipJSON(zw, "summary.json", summary); err != nil {
+		zw.Close()
+		return "", fmt.Errorf("failed to write summary: %v", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish diagnostics file: %v", err)
+	}
+
+	return target, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/sys/windows"
+)
+
+// logDiskLowBytes is the free space below which log-disk-low is emitted for a log drive
+const logDiskLowBytes = 1 << 30
+
+// LogDiskUsage is the space taken by a service's logs and the space left on their drive
+type LogDiskUsage struct {
+	LogPath   string `json:"logPath"`
+	UsedBytes int64  `json:"usedBytes"` // log file plus its rotated backups
+	FreeBytes int64  `json:"freeBytes"` // free space available to the caller on the log drive
+}
+
+// GetLogDiskUsage returns the size of a service's log files and the free space on their drive
+func (wsm *WindowsServiceManager) GetLogDiskUsage(serviceID string) (usedBytes, freeBytes int64, err error) {
+	logPath, _, err := wsm.GetServiceLogPath(serviceID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, path := range append([]string{logPath}, logBackupFiles(logPath)...) {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			usedBytes += info.Size()
+		}
+	}
+
+	freeBytes, err = diskFreeBytes(logVolume(logPath))
+	if err != nil {
+		return usedBytes, 0, err
+	}
+	return usedBytes, freeBytes, nil
+}
+
+// logVolume returns the root of the drive or share holding path
+func logVolume(path string) string {
+	return filepath.VolumeName(path) + `\`
+}
+
+// diskFreeBytes returns the free space available to the caller on the volume with root dir
+func diskFreeBytes(dir string) (int64, error) {
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var freeAvailable, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(dirPtr, &freeAvailable, &total, &totalFree); err != nil {
+		return 0, fmt.Errorf("failed to get free space of %s: %v", dir, err)
+	}
+	return int64(freeAvailable), nil
+}
+
+// checkLogDiskSpace emits log-disk-low when free space on a drive holding service logs drops
+// below logDiskLowBytes. Each drive is reported again only after it recovered.
+// It runs on the status watcher goroutine, which owns lowLogVolumes.
+func (wsm *WindowsServiceManager) checkLogDiskSpace() {
+	wsm.mutex.RLock()
+	volumes := make(map[string][]string)
+	for _, service := range wsm.services {
+		logPath := service.LogPath
+		if logPath == "" {
+			logPath = wsm.defaultLogPath(service.ID)
+		}
+		volume := strings.ToUpper(logVolume(logPath))
+		volumes[volume] = append(volumes[volume], service.ID)
+	}
+	wsm.mutex.RUnlock()
+
+	if wsm.lowLogVolumes == nil {
+		wsm.lowLogVolumes = make(map[string]bool)
+	}
+
+	for volume, serviceIDs := range volumes {
+		free, err := diskFreeBytes(volume)
+		if err != nil {
+			continue
+		}
+
+		if free >= logDiskLowBytes {
+			delete(wsm.lowLogVolumes, volume)
+			continue
+		}
+		if wsm.lowLogVolumes[volume] {
+			continue
+		}
+		wsm.lowLogVolumes[volume] = true
+
+		if wsm.ctx != nil {
+			runtime.EventsEmit(wsm.ctx, "log-disk-low", map[string]interface{}{
+				"volume":     volume,
+				"freeBytes":  free,
+				"serviceIds": serviceIDs,
+			})
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Results of RestartAsAdmin
+const (
+	elevationRestarting = "restarting"
+	elevationCancelled  = "cancelled"
+)
+
+// waitForPIDFlag is passed to an elevated relaunch with the PID of the instance that started it
+const waitForPIDFlag = "--wait-for-pid="
+
+// relaunchWaitTimeout is how long an elevated relaunch waits for the instance that started it to exit
+const relaunchWaitTimeout = 15 * time.Second
+
+// shellExecuteInfo is SHELLEXECUTEINFOW
+type shellExecuteInfo struct {
+	cbSize        uint32
+	mask          uint32
+	hwnd          uintptr
+	verb          *uint16
+	file          *uint16
+	parameters    *uint16
+	directory     *uint16
+	show          int32
+	instApp       uintptr
+	idList        uintptr
+	class         *uint16
+	keyClass      uintptr
+	hotKey        uint32
+	iconOrMonitor uintptr
+	process       windows.Handle
+}
+
+// seeMaskNoCloseProcess asks ShellExecuteEx for a handle to the started process
+const seeMaskNoCloseProcess = 0x00000040
+
+var procShellExecuteExW = windows.NewLazySystemDLL("shell32.dll").NewProc("ShellExecuteExW")
+
+// RestartAsAdmin restarts the application with administrator privileges.
+// It returns "cancelled" when the UAC prompt is declined; on "restarting" the elevated
+// instance is running and this one exits shortly after returning.
+func (a *App) RestartAsAdmin() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	// The elevated instance waits for this one to exit, otherwise the single instance
+	// lock would hand it over to this window and close it
+	var args []string
+	for _, arg := range os.Args[1:] {
+		if !strings.HasPrefix(arg, waitForPIDFlag) {
+			args = append(args, arg)
+		}
+	}
+	args = append(args, waitForPIDFlag+strconv.Itoa(os.Getpid()))
+
+	info := shellExecuteInfo{
+		mask:       seeMaskNoCloseProcess,
+		verb:       windows.StringToUTF16Ptr("runas"),
+		file:       windows.StringToUTF16Ptr(exe),
+		parameters: windows.StringToUTF16Ptr(joinArgs(args)),
+		directory:  windows.StringToUTF16Ptr(cwd),
+		show:       windows.SW_SHOWNORMAL,
+	}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+
+	ret, _, err := procShellExecuteExW.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		if err == windows.ERROR_CANCELLED {
+			return elevationCancelled, nil
+		}
+		return "", fmt.Errorf("failed to start elevated instance: %v", err)
+	}
+	if info.process == 0 {
+		return "", fmt.Errorf("failed to start elevated instance: no process was created")
+	}
+	defer windows.CloseHandle(info.process)
+
+	// The new instance waits for this one, so it exiting right away means it failed
+	event, err := windows.WaitForSingleObject(info.process, 1000)
+	if err == nil && event == windows.WAIT_OBJECT_0 {
+		var exitCode uint32
+		windows.GetExitCodeProcess(info.process, &exitCode)
+		return "", fmt.Errorf("elevated instance exited immediately with code %d", exitCode)
+	}
+
+	// Exit after the result has reached the frontend
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		os.Exit(0)
+	}()
+
+	return elevationRestarting, nil
+}
+
+// waitForRelaunchParent blocks an elevated relaunch until the instance that started it has exited
+func waitForRelaunchParent(args []string) {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, waitForPIDFlag) {
+			continue
+		}
+
+		pid, err := strconv.Atoi(strings.TrimPrefix(arg, waitForPIDFlag))
+		if err != nil {
+			return
+		}
+		handle, err := windows.OpenProcess(windows.SYNCHRONIZE, false, uint32(pid))
+		if err != nil {
+			// Already gone
+			return
+		}
+		windows.WaitForSingleObject(handle, uint32(relaunchWaitTimeout.Milliseconds()))
+		windows.CloseHandle(handle)
+		return
+	}
+}
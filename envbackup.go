@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// environmentBackupDir is the config subdirectory that holds environment backups
+const environmentBackupDir = "env-backups"
+
+// maxEnvironmentBackups is how many automatic backups are kept before the oldest are removed
+const maxEnvironmentBackups = 10
+
+// environmentBackup is the content of a backup file
+type environmentBackup struct {
+	CreatedAt time.Time                         `json:"createdAt"`
+	Values    map[string]environmentBackupValue `json:"values"`
+}
+
+// environmentBackupValue is one environment variable; Expand marks REG_EXPAND_SZ values
+type environmentBackupValue struct {
+	Value  string `json:"value"`
+	Expand bool   `json:"expand"`
+}
+
+// BackupEnvironment writes all system environment variables to a timestamped JSON file
+// in the config directory and returns its path
+func (em *EnvironmentManager) BackupEnvironment() (string, error) {
+	key, err := openEnvironmentKey(environmentScopeSystem, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return "", fmt.Errorf("cannot list environment variables: %v", err)
+	}
+
+	backup := environmentBackup{CreatedAt: time.Now(), Values: make(map[string]environmentBackupValue, len(names))}
+	for _, name := range names {
+		value, valueType, err := key.GetStringValue(name)
+		if err != nil {
+			continue
+		}
+		backup.Values[name] = environmentBackupValue{Value: value, Expand: valueType == registry.EXPAND_SZ}
+	}
+
+	dir, err := configFilePath(environmentBackupDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode environment backup: %v", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("environment-%s.json", backup.CreatedAt.Format("20060102-150405.000")))
+	if err := writeFileAtomic(path, data); err != nil {
+		return "", fmt.Errorf("failed to write environment backup: %v", err)
+	}
+
+	return path, nil
+}
+
+// RestoreEnvironment rewrites the system environment variables from a backup file.
+// Variables that are not in the backup are removed. The current state is backed up first.
+func (em *EnvironmentManager) RestoreEnvironment(backupPath string) error {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to read environment backup: %v", err)
+	}
+
+	var backup environmentBackup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return fmt.Errorf("failed to parse environment backup: %v", err)
+	}
+	if len(backup.Values) == 0 {
+		return fmt.Errorf("environment backup is empty: %s", backupPath)
+	}
+
+	if _, err := em.BackupEnvironment(); err != nil {
+		return fmt.Errorf("failed to back up the current environment before restoring: %v", err)
+	}
+
+	key, err := openEnvironmentKey(environmentScopeSystem, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return fmt.Errorf("cannot list environment variables: %v", err)
+	}
+	for _, name := range names {
+		if _, kept := backup.Values[name]; kept {
+			continue
+		}
+		if err := key.DeleteValue(name); err != nil {
+			return fmt.Errorf("cannot remove environment variable %s: %v", name, err)
+		}
+	}
+
+	for name, value := range backup.Values {
+		if value.Expand {
+			err = key.SetExpandStringValue(name, value.Value)
+		} else {
+			err = key.SetStringValue(name, value.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot set environment variable %s: %v", name, err)
+		}
+	}
+
+	err = em.broadcastEnvironmentChange()
+	if err != nil {
+		return fmt.Errorf("environment restored successfully, but failed to notify system: %v", err)
+	}
+
+	return nil
+}
+
+// backupBeforePathChange takes an automatic backup before the system PATH is modified
+// and prunes old backups. A failed backup is reported but does not block the change.
+func (em *EnvironmentManager) backupBeforePathChange(scope, varName string) {
+	if scope != environmentScopeSystem || !strings.EqualFold(varName, "PATH") {
+		return
+	}
+
+	if _, err := em.BackupEnvironment(); err != nil {
+		fmt.Printf("Warning: failed to back up environment: %v\n", err)
+		return
+	}
+
+	if err := pruneEnvironmentBackups(maxEnvironmentBackups); err != nil {
+		fmt.Printf("Warning: failed to remove old environment backups: %v\n", err)
+	}
+}
+
+// pruneEnvironmentBackups removes all but the newest keep backups
+func pruneEnvironmentBackups(keep int) error {
+	dir, err := configFilePath(environmentBackupDir)
+	if err != nil {
+		return err
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, "environment-*.json"))
+	if err != nil {
+		return err
+	}
+	if len(backups) <= keep {
+		return nil
+	}
+
+	// The timestamp in the name sorts chronologically
+	sort.Strings(backups)
+	for _, path := range backups[:len(backups)-keep] {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
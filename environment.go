@@ -13,59 +13,135 @@ import (
 )
 
 // EnvironmentManager manages environment variables
-type EnvironmentManager struct{}
+type EnvironmentManager struct {
+	maxPathLength int // longest PATH written, in characters
+}
 
 func NewEnvironmentManager() *EnvironmentManager {
-	return &EnvironmentManager{}
+	return &EnvironmentManager{maxPathLength: defaultMaxPathLength}
+}
+
+// defaultMaxPathLength is the longest PATH written by default. Some older programs read
+// expandable environment values into 2048-character buffers and silently cut off the rest.
+const defaultMaxPathLength = 2047
+
+// SetMaxPathLength sets the longest PATH the manager writes; 0 restores the default
+func (em *EnvironmentManager) SetMaxPathLength(length int) error {
+	if length < 0 {
+		return fmt.Errorf("maximum PATH length must not be negative")
+	}
+	if length == 0 {
+		length = defaultMaxPathLength
+	}
+	em.maxPathLength = length
+	return nil
 }
 
-// AddSystemEnvironmentVariable adds a system-level environment variable
+// Environment variable scopes
+const (
+	environmentScopeSystem = "system"
+	environmentScopeUser   = "user"
+)
+
+// openEnvironmentKey opens the environment registry key of a scope.
+// The system scope lives under HKLM and needs administrator rights to modify; the user scope under HKCU does not.
+func openEnvironmentKey(scope string, access uint32) (registry.Key, error) {
+	switch scope {
+	case environmentScopeSystem:
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE,
+			`SYSTEM\CurrentControlSet\Control\Session Manager\Environment`,
+			access)
+		if err != nil {
+			return 0, fmt.Errorf("cannot open system environment registry (administrator rights required): %v", err)
+		}
+		return key, nil
+	case environmentScopeUser:
+		key, _, err := registry.CreateKey(registry.CURRENT_USER, `Environment`, access)
+		if err != nil {
+			return 0, fmt.Errorf("cannot open user environment registry: %v", err)
+		}
+		return key, nil
+	default:
+		return 0, fmt.Errorf("unknown environment scope: %s (use %q or %q)", scope, environmentScopeUser, environmentScopeSystem)
+	}
+}
+
+// AddSystemEnvironmentVariable adds a system-level environment variable.
+// PATH values are appended to the existing PATH; anything else replaces the current value.
 func (em *EnvironmentManager) AddSystemEnvironmentVariable(varName, varValue string) error {
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE,
-		`SYSTEM\CurrentControlSet\Control\Session Manager\Environment`,
-		registry.ALL_ACCESS)
-	if err != nil {
-		return fmt.Errorf("cannot open system environment registry (administrator rights required): %v", err)
+	return em.AddEnvironmentVariable(environmentScopeSystem, varName, varValue)
+}
+
+// AddUserEnvironmentVariable adds an environment variable for the current user
+func (em *EnvironmentManager) AddUserEnvironmentVariable(varName, varValue string) error {
+	return em.AddEnvironmentVariable(environmentScopeUser, varName, varValue)
+}
+
+// AddEnvironmentVariable adds an environment variable in scope ("user" or "system").
+// PATH values are appended to the existing PATH; anything else replaces the current value.
+func (em *EnvironmentManager) AddEnvironmentVariable(scope, varName, varValue string) error {
+	if strings.ToUpper(varName) != "PATH" {
+		return em.SetEnvironmentVariable(scope, varName, varValue)
 	}
-	defer key.Close()
 
-	var valueType uint32
-	if strings.ToUpper(varName) == "PATH" || strings.Contains(varValue, "%") {
-		valueType = registry.EXPAND_SZ
-	} else {
-		valueType = registry.SZ
+	key, err := openEnvironmentKey(scope, registry.QUERY_VALUE)
+	if err != nil {
+		return err
+	}
+	existingPath, _, readErr := key.GetStringValue(varName)
+	key.Close()
+	if readErr != nil && readErr != registry.ErrNotExist {
+		return fmt.Errorf("cannot read existing PATH variable: %v", readErr)
 	}
 
 	// Special handling for PATH variable
-	if strings.ToUpper(varName) == "PATH" {
-		var existingPath string
-		var readErr error
-
-		existingPath, _, readErr = key.GetStringValue("PATH")
-		if readErr != nil && readErr != registry.ErrNotExist {
-			return fmt.Errorf("cannot read existing PATH variable: %v", readErr)
+	if existingPath != "" {
+		pathEntries := strings.Split(existingPath, ";")
+		for _, entry := range pathEntries {
+			if strings.EqualFold(strings.TrimSpace(entry), strings.TrimSpace(varValue)) {
+				return fmt.Errorf("path already exists in PATH: %s", varValue)
+			}
 		}
 
-		if existingPath != "" {
-			pathEntries := strings.Split(existingPath, ";")
-			for _, entry := range pathEntries {
-				if strings.EqualFold(strings.TrimSpace(entry), strings.TrimSpace(varValue)) {
-					return fmt.Errorf("path already exists in PATH: %s", varValue)
-				}
-			}
+		if !strings.HasSuffix(existingPath, ";") {
+			varValue = existingPath + ";" + varValue
+		} else {
+			varValue = existingPath + varValue
 		}
+	}
 
-		if existingPath != "" {
-			if !strings.HasSuffix(existingPath, ";") {
-				varValue = existingPath + ";" + varValue
-			} else {
-				varValue = existingPath + varValue
-			}
+	em.backupBeforePathChange(scope, varName)
+
+	return em.SetEnvironmentVariable(scope, varName, varValue)
+}
+
+// SetSystemEnvironmentVariable creates or replaces a system-level environment variable
+func (em *EnvironmentManager) SetSystemEnvironmentVariable(varName, varValue string) error {
+	return em.SetEnvironmentVariable(environmentScopeSystem, varName, varValue)
+}
+
+// SetUserEnvironmentVariable creates or replaces an environment variable for the current user
+func (em *EnvironmentManager) SetUserEnvironmentVariable(varName, varValue string) error {
+	return em.SetEnvironmentVariable(environmentScopeUser, varName, varValue)
+}
+
+// SetEnvironmentVariable creates or replaces an environment variable in scope ("user" or "system")
+func (em *EnvironmentManager) SetEnvironmentVariable(scope, varName, varValue string) error {
+	key, err := openEnvironmentKey(scope, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	isPath := strings.ToUpper(varName) == "PATH"
+	if isPath {
+		if err := em.checkPathLength(varValue); err != nil {
+			return err
 		}
 	}
 
 	// Set registry value
-	if valueType == registry.EXPAND_SZ {
+	if isPath || strings.Contains(varValue, "%") {
 		err = key.SetExpandStringValue(varName, varValue)
 	} else {
 		err = key.SetStringValue(varName, varValue)
@@ -75,6 +151,12 @@ func (em *EnvironmentManager) AddSystemEnvironmentVariable(varName, varValue str
 		return fmt.Errorf("cannot set environment variable: %v", err)
 	}
 
+	if isPath {
+		if err := verifyPathWritten(scope, varName, varValue); err != nil {
+			return err
+		}
+	}
+
 	// Immediately notify system that environment variable has changed
 	err = em.broadcastEnvironmentChange()
 	if err != nil {
@@ -84,8 +166,88 @@ func (em *EnvironmentManager) AddSystemEnvironmentVariable(varName, varValue str
 	return nil
 }
 
+// checkPathLength refuses a PATH value longer than the configured maximum
+func (em *EnvironmentManager) checkPathLength(value string) error {
+	length := len(windows.StringToUTF16(value)) - 1
+	if length <= em.maxPathLength {
+		return nil
+	}
+	return fmt.Errorf("%w: %d characters, the limit is %d; remove unused entries with CleanPath, "+
+		"or add the directory as a PATH segment variable referenced from PATH", ErrPathTooLong, length, em.maxPathLength)
+}
+
+// verifyPathWritten reads PATH back and checks that the registry holds exactly what was written
+func verifyPathWritten(scope, varName, expected string) error {
+	key, err := openEnvironmentKey(scope, registry.QUERY_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	// GetStringValue leaves %VARS% in REG_EXPAND_SZ values unexpanded
+	written, _, err := key.GetStringValue(varName)
+	if err != nil {
+		return fmt.Errorf("cannot read PATH back after writing it: %v", err)
+	}
+	if written != expected {
+		return fmt.Errorf("PATH was not stored as written: wrote %d characters, read back %d", len(expected), len(written))
+	}
+	return nil
+}
+
+// AddPathSegment adds a directory to the PATH of scope ("user" or "system") through a variable
+// of its own: segmentName is set to the directory and %segmentName% is appended to PATH, which
+// keeps PATH short when the directory itself would push it over the limit
+func (em *EnvironmentManager) AddPathSegment(scope, segmentName, pathValue string) error {
+	pathValue = strings.Trim(pathValue, "\"")
+	if !filepath.IsAbs(pathValue) {
+		return fmt.Errorf("absolute path must be provided")
+	}
+	if segmentName == "" || strings.ContainsAny(segmentName, "=%;") || strings.EqualFold(segmentName, "PATH") {
+		return fmt.Errorf("invalid PATH segment variable name: %q", segmentName)
+	}
+	if strings.Contains(pathValue, "%") {
+		// Windows expands variables referenced from PATH only one level deep
+		return fmt.Errorf("a PATH segment cannot contain environment variables")
+	}
+
+	if _, err := em.GetEnvironmentVariable(scope, segmentName); err == nil {
+		return fmt.Errorf("environment variable already exists: %s", segmentName)
+	}
+
+	// Check PATH first so a failure does not leave the segment variable behind
+	key, err := openEnvironmentKey(scope, registry.QUERY_VALUE)
+	if err != nil {
+		return err
+	}
+	existingPath, _, readErr := key.GetStringValue("PATH")
+	key.Close()
+	if readErr != nil && readErr != registry.ErrNotExist {
+		return fmt.Errorf("cannot read existing PATH variable: %v", readErr)
+	}
+	reference := "%" + segmentName + "%"
+	if err := em.checkPathLength(strings.TrimSuffix(existingPath, ";") + ";" + reference); err != nil {
+		return err
+	}
+
+	if err := em.SetEnvironmentVariable(scope, segmentName, pathValue); err != nil {
+		return err
+	}
+	return em.AddEnvironmentVariable(scope, "PATH", reference)
+}
+
 // AddPathVariable specifically adds a PATH environment variable
 func (em *EnvironmentManager) AddPathVariable(pathValue string) error {
+	return em.AddScopedPathVariable(environmentScopeSystem, pathValue)
+}
+
+// AddUserPathVariable adds a directory to the current user's PATH
+func (em *EnvironmentManager) AddUserPathVariable(pathValue string) error {
+	return em.AddScopedPathVariable(environmentScopeUser, pathValue)
+}
+
+// AddScopedPathVariable adds a directory to the PATH of scope ("user" or "system")
+func (em *EnvironmentManager) AddScopedPathVariable(scope, pathValue string) error {
 	pathValue = strings.Trim(pathValue, "\"")
 
 	if !filepath.IsAbs(pathValue) {
@@ -96,7 +258,199 @@ func (em *EnvironmentManager) AddPathVariable(pathValue string) error {
 		pathValue = filepath.Dir(pathValue)
 	}
 
-	return em.AddSystemEnvironmentVariable("PATH", pathValue)
+	return em.AddEnvironmentVariable(scope, "PATH", pathValue)
+}
+
+// RemoveSystemEnvironmentVariable deletes a system-level environment variable
+func (em *EnvironmentManager) RemoveSystemEnvironmentVariable(varName string) error {
+	return em.RemoveEnvironmentVariable(environmentScopeSystem, varName)
+}
+
+// RemoveUserEnvironmentVariable deletes an environment variable of the current user
+func (em *EnvironmentManager) RemoveUserEnvironmentVariable(varName string) error {
+	return em.RemoveEnvironmentVariable(environmentScopeUser, varName)
+}
+
+// RemoveEnvironmentVariable deletes an environment variable in scope ("user" or "system")
+func (em *EnvironmentManager) RemoveEnvironmentVariable(scope, varName string) error {
+	key, err := openEnvironmentKey(scope, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	em.backupBeforePathChange(scope, varName)
+
+	err = key.DeleteValue(varName)
+	if err == registry.ErrNotExist {
+		return fmt.Errorf("environment variable does not exist: %s", varName)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot remove environment variable: %v", err)
+	}
+
+	err = em.broadcastEnvironmentChange()
+	if err != nil {
+		return fmt.Errorf("environment variable removed successfully, but failed to notify system: %v", err)
+	}
+
+	return nil
+}
+
+// RemovePathEntry removes a directory from the system PATH variable
+func (em *EnvironmentManager) RemovePathEntry(pathValue string) error {
+	return em.RemoveScopedPathEntry(environmentScopeSystem, pathValue)
+}
+
+// RemoveUserPathEntry removes a directory from the current user's PATH variable
+func (em *EnvironmentManager) RemoveUserPathEntry(pathValue string) error {
+	return em.RemoveScopedPathEntry(environmentScopeUser, pathValue)
+}
+
+// RemoveScopedPathEntry removes a directory from the PATH of scope ("user" or "system")
+func (em *EnvironmentManager) RemoveScopedPathEntry(scope, pathValue string) error {
+	pathValue = strings.TrimSpace(strings.Trim(pathValue, "\""))
+
+	key, err := openEnvironmentKey(scope, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	existingPath, _, err := key.GetStringValue("PATH")
+	if err != nil {
+		return fmt.Errorf("cannot read existing PATH variable: %v", err)
+	}
+
+	var kept []string
+	found := false
+	for _, entry := range strings.Split(existingPath, ";") {
+		if strings.EqualFold(strings.TrimSpace(entry), pathValue) {
+			found = true
+			continue
+		}
+		if strings.TrimSpace(entry) != "" {
+			kept = append(kept, entry)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("path does not exist in PATH: %s", pathValue)
+	}
+
+	em.backupBeforePathChange(scope, "PATH")
+
+	err = key.SetExpandStringValue("PATH", strings.Join(kept, ";"))
+	if err != nil {
+		return fmt.Errorf("cannot set environment variable: %v", err)
+	}
+
+	err = em.broadcastEnvironmentChange()
+	if err != nil {
+		return fmt.Errorf("path removed successfully, but failed to notify system: %v", err)
+	}
+
+	return nil
+}
+
+// CleanPath removes duplicate entries, and with removeDead entries whose directory no longer exists,
+// from the system PATH variable. It returns the removed entries.
+func (em *EnvironmentManager) CleanPath(removeDead bool) ([]string, error) {
+	return em.CleanScopedPath(environmentScopeSystem, removeDead)
+}
+
+// CleanUserPath removes duplicate and optionally dead entries from the current user's PATH variable
+func (em *EnvironmentManager) CleanUserPath(removeDead bool) ([]string, error) {
+	return em.CleanScopedPath(environmentScopeUser, removeDead)
+}
+
+// CleanScopedPath removes duplicate and optionally dead entries from the PATH of scope ("user" or "system").
+// Duplicates are compared case-insensitively and the first occurrence is kept.
+func (em *EnvironmentManager) CleanScopedPath(scope string, removeDead bool) ([]string, error) {
+	key, err := openEnvironmentKey(scope, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	existingPath, _, err := key.GetStringValue("PATH")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read existing PATH variable: %v", err)
+	}
+
+	removed := make([]string, 0)
+	var kept []string
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(existingPath, ";") {
+		normalized := strings.ToLower(strings.TrimRight(strings.Trim(strings.TrimSpace(entry), "\""), `\`))
+		if normalized == "" {
+			continue
+		}
+		if seen[normalized] {
+			removed = append(removed, entry)
+			continue
+		}
+		if removeDead && em.isDeadPathEntry(entry) {
+			removed = append(removed, entry)
+			continue
+		}
+		seen[normalized] = true
+		kept = append(kept, entry)
+	}
+
+	cleaned := strings.Join(kept, ";")
+	if cleaned == existingPath {
+		return removed, nil
+	}
+
+	em.backupBeforePathChange(scope, "PATH")
+
+	err = key.SetExpandStringValue("PATH", cleaned)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set environment variable: %v", err)
+	}
+
+	err = em.broadcastEnvironmentChange()
+	if err != nil {
+		return removed, fmt.Errorf("PATH cleaned successfully, but failed to notify system: %v", err)
+	}
+
+	return removed, nil
+}
+
+// isDeadPathEntry reports whether a PATH entry names a directory that does not exist.
+// Entries with variables that cannot be expanded are kept, since they may resolve elsewhere.
+func (em *EnvironmentManager) isDeadPathEntry(entry string) bool {
+	expanded, err := registry.ExpandString(strings.TrimSpace(strings.Trim(entry, "\"")))
+	if err != nil || strings.Contains(expanded, "%") {
+		return false
+	}
+	return !em.ValidatePathExists(expanded)
+}
+
+// ListEnvironmentVariables returns all environment variables stored in scope ("user" or "system")
+func (em *EnvironmentManager) ListEnvironmentVariables(scope string) (map[string]string, error) {
+	key, err := openEnvironmentKey(scope, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list environment variables: %v", err)
+	}
+
+	variables := make(map[string]string, len(names))
+	for _, name := range names {
+		value, _, err := key.GetStringValue(name)
+		if err != nil {
+			continue
+		}
+		variables[name] = value
+	}
+
+	return variables, nil
 }
 
 // broadcastEnvironmentChange broadcasts environment change message
@@ -146,11 +500,19 @@ func (em *EnvironmentManager) ValidatePathExists(path string) bool {
 
 // GetSystemEnvironmentVariable gets a system environment variable value
 func (em *EnvironmentManager) GetSystemEnvironmentVariable(varName string) (string, error) {
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE,
-		`SYSTEM\CurrentControlSet\Control\Session Manager\Environment`,
-		registry.QUERY_VALUE)
+	return em.GetEnvironmentVariable(environmentScopeSystem, varName)
+}
+
+// GetUserEnvironmentVariable gets an environment variable value of the current user
+func (em *EnvironmentManager) GetUserEnvironmentVariable(varName string) (string, error) {
+	return em.GetEnvironmentVariable(environmentScopeUser, varName)
+}
+
+// GetEnvironmentVariable gets an environment variable value in scope ("user" or "system")
+func (em *EnvironmentManager) GetEnvironmentVariable(scope, varName string) (string, error) {
+	key, err := openEnvironmentKey(scope, registry.QUERY_VALUE)
 	if err != nil {
-		return "", fmt.Errorf("cannot open system environment registry: %v", err)
+		return "", err
 	}
 	defer key.Close()
 
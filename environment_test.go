@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckPathLength(t *testing.T) {
+	em := NewEnvironmentManager()
+	limit := defaultMaxPathLength
+
+	tests := []struct {
+		name    string
+		value   string
+		tooLong bool
+	}{
+		{"empty", "", false},
+		{"well under the limit", `C:\Windows;C:\Windows\System32`, false},
+		{"one under the limit", strings.Repeat("a", limit-1), false},
+		{"at the limit", strings.Repeat("a", limit), false},
+		{"one over the limit", strings.Repeat("a", limit+1), true},
+		{"far over the limit", strings.Repeat(`C:\Program Files\Tool\bin;`, 200), true},
+		// Characters outside the BMP take two UTF-16 code units
+		{"surrogate pairs at the limit", strings.Repeat("a", limit-2) + "😀", false},
+		{"surrogate pairs over the limit", strings.Repeat("a", limit-1) + "😀", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := em.checkPathLength(tt.value)
+			if tt.tooLong && !errors.Is(err, ErrPathTooLong) {
+				t.Errorf("checkPathLength = %v, want ErrPathTooLong", err)
+			}
+			if !tt.tooLong && err != nil {
+				t.Errorf("checkPathLength = %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestSetMaxPathLength(t *testing.T) {
+	em := NewEnvironmentManager()
+
+	if err := em.SetMaxPathLength(100); err != nil {
+		t.Fatalf("SetMaxPathLength: %v", err)
+	}
+	if err := em.checkPathLength(strings.Repeat("a", 100)); err != nil {
+		t.Errorf("PATH at a custom limit refused: %v", err)
+	}
+	if err := em.checkPathLength(strings.Repeat("a", 101)); !errors.Is(err, ErrPathTooLong) {
+		t.Errorf("PATH over a custom limit = %v, want ErrPathTooLong", err)
+	}
+
+	if err := em.SetMaxPathLength(0); err != nil {
+		t.Fatalf("SetMaxPathLength(0): %v", err)
+	}
+	if em.maxPathLength != defaultMaxPathLength {
+		t.Errorf("SetMaxPathLength(0) set %d, want the default %d", em.maxPathLength, defaultMaxPathLength)
+	}
+
+	if err := em.SetMaxPathLength(-1); err == nil {
+		t.Errorf("SetMaxPathLength accepted a negative length")
+	}
+}
+
+func TestSetEnvironmentVariableRefusesLongPath(t *testing.T) {
+	em := NewEnvironmentManager()
+	if err := em.SetMaxPathLength(10); err != nil {
+		t.Fatalf("SetMaxPathLength: %v", err)
+	}
+
+	// Refused before anything is written, so the user's PATH is left alone
+	err := em.SetEnvironmentVariable(environmentScopeUser, "Path", `C:\far\too\long\for\the\limit`)
+	if !errors.Is(err, ErrPathTooLong) {
+		t.Errorf("SetEnvironmentVariable = %v, want ErrPathTooLong", err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// Errors the frontend can tell apart through their error code
+var (
+	ErrServiceNotFound       = errors.New("service does not exist")
+	ErrServiceAlreadyRunning = errors.New("service is already running")
+	ErrServiceAlreadyStopped = errors.New("service is not running")
+	ErrTimeout               = errors.New("timeout")
+	ErrPortInUse             = errors.New("port is already in use")
+	ErrPathTooLong           = errors.New("PATH would be too long")
+
+	// ErrAccessDenied is returned when SCM or the registry refuses access, usually because the
+	// app is not running as administrator; the frontend can offer RestartAsAdmin when it sees it
+	ErrAccessDenied = errors.New("access denied, administrator privileges are required")
+)
+
+// Error codes sent to the frontend
+const (
+	errorCodeServiceNotFound       = "SERVICE_NOT_FOUND"
+	errorCodeServiceAlreadyRunning = "SERVICE_ALREADY_RUNNING"
+	errorCodeServiceAlreadyStopped = "SERVICE_ALREADY_STOPPED"
+	errorCodeAccessDenied          = "ACCESS_DENIED"
+	errorCodeTimeout               = "TIMEOUT"
+	errorCodePortInUse             = "PORT_IN_USE"
+	errorCodePathTooLong           = "PATH_TOO_LONG"
+	errorCodeUnknown               = "UNKNOWN"
+)
+
+// AppError is how errors returned by App methods reach the frontend
+type AppError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// wrapAccessError describes a failed action, turning ERROR_ACCESS_DENIED into ErrAccessDenied
+func wrapAccessError(action string, err error) error {
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return fmt.Errorf("%s: %w", action, ErrAccessDenied)
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// errorCode classifies err into one of the stable error codes
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrServiceNotFound):
+		return errorCodeServiceNotFound
+	case errors.Is(err, ErrServiceAlreadyRunning):
+		return errorCodeServiceAlreadyRunning
+	case errors.Is(err, ErrServiceAlreadyStopped):
+		return errorCodeServiceAlreadyStopped
+	case errors.Is(err, ErrAccessDenied), errors.Is(err, windows.ERROR_ACCESS_DENIED):
+		return errorCodeAccessDenied
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return errorCodeTimeout
+	case errors.Is(err, ErrPortInUse):
+		return errorCodePortInUse
+	case errors.Is(err, ErrPathTooLong):
+		return errorCodePathTooLong
+	default:
+		return errorCodeUnknown
+	}
+}
+
+// formatError is the Wails error formatter; bound methods reject with an AppError instead of a plain string
+func formatError(err error) any {
+	return AppError{Code: errorCode(err), Message: err.Error()}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+func TestWrapAccessError(t *testing.T) {
+	err := wrapAccessError("failed to set registry value", windows.ERROR_ACCESS_DENIED)
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("access denied is not reported as ErrAccessDenied: %v", err)
+	}
+	if code := errorCode(err); code != errorCodeAccessDenied {
+		t.Errorf("errorCode = %s, want %s", code, errorCodeAccessDenied)
+	}
+
+	err = wrapAccessError("failed to open service registry key", registry.ErrNotExist)
+	if !errors.Is(err, registry.ErrNotExist) {
+		t.Errorf("other errors are not kept in the chain: %v", err)
+	}
+}
+
+func TestRegistryErrorsKeepTheirCause(t *testing.T) {
+	wsm := newTestManager(t, newFakeConnector())
+	const missing = "WSM_test_service_that_does_not_exist"
+
+	err := wsm.setServiceRegistryValue(missing, "Parameters", "ExePath", `C:\app\app.exe`)
+	if !errors.Is(err, registry.ErrNotExist) {
+		t.Errorf("setServiceRegistryValue = %v, want it to wrap ErrNotExist", err)
+	}
+
+	_, err = wsm.createServiceWrapper(missing, ServiceConfig{ExePath: `C:\app\app.exe`})
+	if !errors.Is(err, registry.ErrNotExist) {
+		t.Errorf("createServiceWrapper = %v, want it to wrap ErrNotExist", err)
+	}
+
+	err = checkWrapperService(missing)
+	if !errors.Is(err, registry.ErrNotExist) {
+		t.Errorf("checkWrapperService = %v, want it to wrap ErrNotExist", err)
+	}
+}
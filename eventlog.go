@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/sys/windows/svc/debug"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// Event IDs written by the wrapper to the Windows Event Log
+const (
+	eventIDStarted     = 1
+	eventIDStopped     = 2
+	eventIDExited      = 3
+	eventIDRestarting  = 4
+	eventIDStartFailed = 5
+	eventIDLogDiskFull = 6
+)
+
+// Event levels accepted by logEvent
+const (
+	eventInfo    = "info"
+	eventWarning = "warning"
+	eventError   = "error"
+)
+
+// openWrapperEventLog registers serviceName as an event source and opens it.
+// Outside SCM (debug mode) events go to the console instead.
+func openWrapperEventLog(serviceName string, isService bool) debug.Log {
+	if !isService {
+		return debug.New(serviceName)
+	}
+
+	err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		log.Printf("Failed to register event source: %v", err)
+	}
+
+	elog, err := eventlog.Open(serviceName)
+	if err != nil {
+		log.Printf("Failed to open event log: %v", err)
+		return nil
+	}
+	return elog
+}
+
+// removeEventSource unregisters the event source of a deleted service
+func removeEventSource(serviceName string) {
+	if err := eventlog.Remove(serviceName); err != nil && !strings.Contains(err.Error(), "cannot find") {
+		fmt.Printf("Warning: failed to remove event source of %s: %v\n", serviceName, err)
+	}
+}
+
+// logEvent writes a lifecycle event to the log and, when available, the Windows Event Log
+func (esw *EmbeddedServiceWrapper) logEvent(level string, eventID uint32, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	log.Print(message)
+
+	if esw.elog == nil {
+		return
+	}
+
+	var err error
+	switch level {
+	case eventError:
+		err = esw.elog.Error(eventID, message)
+	case eventWarning:
+		err = esw.elog.Warning(eventID, message)
+	default:
+		err = esw.elog.Info(eventID, message)
+	}
+	if err != nil {
+		log.Printf("Failed to write event log entry: %v", err)
+	}
+}
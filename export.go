@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ServiceDefinition is the portable description of a service used for export and import.
+// It leaves out the service ID so imported services get fresh names on the target machine.
+type ServiceDefinition struct {
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	ExePath        string `json:"exePath"`
+	Args           string `json:"args"`
+	WorkingDir     string `json:"workingDir"`
+	LogPath        string `json:"logPath,omitempty"` // empty when the service used the default log location
+	ErrorLogPath   string `json:"errorLogPath,omitempty"`
+	LoadOrderGroup string `json:"loadOrderGroup,omitempty"`
+	AutoStart      bool   `json:"autoStart"`
+	StartType      string `json:"startType,omitempty"`
+}
+
+// ServiceExport is the top-level document written by ExportServices
+type ServiceExport struct {
+	ExportedAt time.Time           `json:"exportedAt"`
+	Services   []ServiceDefinition `json:"services"`
+}
+
+// ExportServices serializes the definitions of all managed services to JSON
+func (a *App) ExportServices() (string, error) {
+	services, err := a.serviceManager.GetServices()
+	if err != nil {
+		return "", err
+	}
+
+	export := ServiceExport{
+		ExportedAt: time.Now(),
+		Services:   make([]ServiceDefinition, 0, len(services)),
+	}
+	for _, service := range services {
+		logPath := service.LogPath
+		if a.serviceManager.isDefaultLogPath(service.ID, logPath) {
+			logPath = ""
+		}
+
+		export.Services = append(export.Services, ServiceDefinition{
+			Name:           service.Name,
+			Description:    service.Description,
+			ExePath:        service.ExePath,
+			Args:           service.Args,
+			WorkingDir:     service.WorkingDir,
+			LogPath:        logPath,
+			ErrorLogPath:   service.ErrorLogPath,
+			LoadOrderGroup: service.LoadOrderGroup,
+			AutoStart:      service.AutoStart,
+			StartType:      service.StartType,
+		})
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize services: %v", err)
+	}
+	return string(data), nil
+}
+
+// ImportServices recreates the services described by data under newly generated names.
+// Definitions whose executable does not exist on this machine are skipped.
+func (a *App) ImportServices(data string) ([]*Service, error) {
+	var export ServiceExport
+	if err := json.Unmarshal([]byte(data), &export); err != nil {
+		return nil, fmt.Errorf("invalid service export: %v", err)
+	}
+
+	imported := make([]*Service, 0, len(export.Services))
+	for _, definition := range export.Services {
+		if _, err := os.Stat(expandServicePath(definition.ExePath)); err != nil {
+			fmt.Printf("Warning: skipping %s, executable not found: %s\n", definition.Name, definition.ExePath)
+			continue
+		}
+
+		service, err := a.serviceManager.CreateService(ServiceConfig{
+			Name:           definition.Name,
+			Description:    definition.Description,
+			ExePath:        definition.ExePath,
+			Args:           definition.Args,
+			WorkingDir:     definition.WorkingDir,
+			LogPath:        definition.LogPath,
+			ErrorLogPath:   definition.ErrorLogPath,
+			LoadOrderGroup: definition.LoadOrderGroup,
+		})
+		if err != nil {
+			fmt.Printf("Warning: failed to import %s: %v\n", definition.Name, err)
+			continue
+		}
+
+		startType := definition.StartType
+		if startType == "" && definition.AutoStart {
+			startType = "auto"
+		}
+		if startType != "" {
+			if err := a.serviceManager.SetServiceStartType(service.ID, startType); err != nil {
+				fmt.Printf("Warning: failed to set start type of %s: %v\n", service.ID, err)
+			}
+		}
+
+		imported = append(imported, service)
+	}
+
+	return imported, nil
+}
+
+// ExportServicesToFile writes the service definitions to a user-chosen file.
+// It returns the written path, or an empty string if the dialog was cancelled.
+func (a *App) ExportServicesToFile() (string, error) {
+	data, err := a.ExportServices()
+	if err != nil {
+		return "", err
+	}
+
+	target, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Services",
+		DefaultFilename: fmt.Sprintf("services_%s.json", time.Now().Format("20060102-150405")),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "JSON Files (*.json)",
+				Pattern:     "*.json",
+			},
+		},
+	})
+	if err != nil || target == "" {
+		return "", err
+	}
+
+	if err := os.WriteFile(target, []byte(data), 0644); err != nil {
+		return "", fmt.Errorf("failed to write export file: %v", err)
+	}
+
+	return target, nil
+}
+
+// ImportServicesFromFile imports service definitions from a user-chosen file.
+// It returns no services if the dialog was cancelled.
+func (a *App) ImportServicesFromFile() ([]*Service, error) {
+	source, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import Services",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "JSON Files (*.json)",
+				Pattern:     "*.json",
+			},
+		},
+	})
+	if err != nil || source == "" {
+		return []*Service{}, err
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %v", err)
+	}
+
+	return a.ImportServices(string(data))
+}
@@ -7,18 +7,24 @@ import {
   StartService, 
   StopService, 
   DeleteService,
+  DeleteServiceAndData,
+  RequestServiceDeletion,
   SelectFile,
   SelectDirectory,
   CheckAdminPrivileges,
   SetAutoStart,
   GetAutoStartStatus,
+  GetNotifyOnCrash,
+  SetNotifyOnCrash,
   SetServiceAutoStart,
   RestartAsAdmin,
   AddPathVariable,
   OpenSystemEnvironmentSettings,
   ValidatePathExists,
   DiagnoseEnvironmentAccess,
-  StartMonitoringService
+  StartMonitoringService,
+  StopAllServices,
+  TestRunService
 } from "../wailsjs/go/main/App";
 import {
   makeStyles,
@@ -58,6 +64,9 @@ import './App.css';
 import ServiceRow from './components/ServiceRow'; 
 import ServiceLogViewer from './components/ServiceLogViewer'; 
 
+// Backend errors arrive as { code, message }; anything else is shown as is
+const errorMessage = (error) => error?.message ?? String(error);
+
 const useStyles = makeStyles({
   toastGrid: {
     display: 'grid',
@@ -81,13 +90,16 @@ function App() {
   const [isDeleteDialogOpen, setIsDeleteDialogOpen] = useState(false);
   const [isEnvDialogOpen, setIsEnvDialogOpen] = useState(false);
   const [serviceToDelete, setServiceToDelete] = useState(null);
+  const [purgeOnDelete, setPurgeOnDelete] = useState(false);
   const [adminPrivileges, setAdminPrivileges] = useState(false);
   const [autoStart, setAutoStart] = useState(false);
+  const [notifyOnCrash, setNotifyOnCrash] = useState(false);
   const [showAdminWarning, setShowAdminWarning] = useState(false);
   const [envPath, setEnvPath] = useState('');
   const [isAddingEnv, setIsAddingEnv] = useState(false);
   const [newService, setNewService] = useState({
     name: '',
+    description: '',
     exePath: '',
     args: '',
     workingDir: ''
@@ -109,6 +121,7 @@ function App() {
     loadServices();
     checkAdminRights();
     checkAutoStartStatus();
+    GetNotifyOnCrash().then(setNotifyOnCrash).catch(() => {});
     
     // Listen for service status change events
     EventsOn('service-status-changed', (data) => {
@@ -124,9 +137,16 @@ function App() {
       setServices(serviceList || []);
     });
     
+    // Warn when a drive holding service logs runs low on space
+    EventsOn('log-disk-low', (data) => {
+      const freeMB = Math.round(data.freeBytes / (1024 * 1024));
+      showToast('Low disk space', `Only ${freeMB} MB left on ${data.volume} for service logs`, 'warning');
+    });
+    
     return () => {
       EventsOff('service-status-changed');
       EventsOff('services-updated');
+      EventsOff('log-disk-low');
     };
   }, []);
 
@@ -157,15 +177,30 @@ function App() {
       setAutoStart(enabled);
       showToast('Success', `Auto-start ${enabled ? 'enabled' : 'disabled'}`);
     } catch (error) {
-      showToast('Error', 'Failed to set auto-start: ' + error, 'error');
+      showToast('Error', 'Failed to set auto-start: ' + errorMessage(error), 'error');
+    }
+  }, [showToast]);
+
+  const handleNotifyOnCrashToggle = useCallback(async (enabled) => {
+    try {
+      await SetNotifyOnCrash(enabled);
+      setNotifyOnCrash(enabled);
+      showToast('Success', `Crash notifications ${enabled ? 'enabled' : 'disabled'}`);
+    } catch (error) {
+      showToast('Error', 'Failed to set crash notifications: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
   const handleRestartAsAdmin = useCallback(async () => {
     try {
-      await RestartAsAdmin();
+      const result = await RestartAsAdmin();
+      if (result === 'cancelled') {
+        showToast('Elevation cancelled', 'The application keeps running without administrator privileges', 'warning');
+      } else {
+        showToast('Restarting', 'Restarting as administrator...');
+      }
     } catch (error) {
-      showToast('Error', 'Failed to restart as administrator: ' + error, 'error');
+      showToast('Error', 'Failed to restart as administrator: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
@@ -174,10 +209,26 @@ function App() {
       const serviceList = await GetServices();
       setServices(serviceList || []);
     } catch (error) {
-      showToast('Error', 'Failed to load service list: ' + error, 'error');
+      showToast('Error', 'Failed to load service list: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
+  const handleStopAll = useCallback(async () => {
+    try {
+      const results = await StopAllServices();
+      const failed = Object.entries(results || {}).filter(([, message]) => message);
+      if (failed.length === 0) {
+        showToast('Success', 'All services stopped');
+      } else {
+        showToast('Warning', `${failed.length} service(s) could not be stopped: ` +
+          failed.map(([id, message]) => `${id}: ${message}`).join('; '), 'warning');
+      }
+      loadServices();
+    } catch (error) {
+      showToast('Error', 'Failed to stop services: ' + errorMessage(error), 'error');
+    }
+  }, [showToast, loadServices]);
+
   const handleCreateService = useCallback(async () => {
     if (!newService.name || !newService.exePath) {
       showToast('Validation error', 'Please enter service name and executable path', 'error');
@@ -190,23 +241,45 @@ function App() {
       setIsAddDialogOpen(false);
       setNewService({
         name: '',
+        description: '',
         exePath: '',
         args: '',
         workingDir: ''
       });
       loadServices();
     } catch (error) {
-      showToast('Error', 'Failed to create service: ' + error, 'error');
+      showToast('Error', 'Failed to create service: ' + errorMessage(error), 'error');
     }
   }, [newService, showToast, loadServices]);
 
+  const handleTestRun = useCallback(async () => {
+    if (!newService.exePath) {
+      showToast('Validation error', 'Please enter the executable path', 'error');
+      return;
+    }
+
+    try {
+      const result = await TestRunService(newService, 0);
+      const lastLines = (result.output || '').trim().split('\n').slice(-5).join('\n');
+      if (result.timedOut) {
+        showToast('Test run', `Still running after ${Math.round(result.durationMs / 1000)}s, stopped it`);
+      } else if (result.exitCode === 0) {
+        showToast('Test run', 'Exited with code 0' + (lastLines ? ':\n' + lastLines : ''));
+      } else {
+        showToast('Test run failed', `Exited with code ${result.exitCode}` + (result.hint ? ': ' + result.hint : '') + (lastLines ? '\n' + lastLines : ''), 'error');
+      }
+    } catch (error) {
+      showToast('Error', 'Test run failed: ' + errorMessage(error), 'error');
+    }
+  }, [newService, showToast]);
+
   const handleStartService = useCallback(async (serviceId) => {
     try {
       await StartService(serviceId);
       showToast('Success', 'Service started successfully');
       loadServices();
     } catch (error) {
-      showToast('Error', 'Failed to start service: ' + error, 'error');
+      showToast('Error', 'Failed to start service: ' + errorMessage(error), 'error');
     }
   }, [showToast, loadServices]);
 
@@ -216,30 +289,41 @@ function App() {
       showToast('Success', 'Service stopped successfully');
       loadServices();
     } catch (error) {
-      showToast('Error', 'Failed to stop service: ' + error, 'error');
+      showToast('Error', 'Failed to stop service: ' + errorMessage(error), 'error');
     }
   }, [showToast, loadServices]);
 
-  const handleDeleteService = useCallback((serviceId) => {
-    const service = services.find(s => s.id === serviceId);
-    setServiceToDelete(service);
-    setIsDeleteDialogOpen(true);
-  }, [services]);
+  const handleDeleteService = useCallback(async (serviceId) => {
+    try {
+      const request = await RequestServiceDeletion(serviceId);
+      setServiceToDelete({ id: serviceId, ...request });
+      setIsDeleteDialogOpen(true);
+    } catch (error) {
+      showToast('Error', 'Failed to delete service: ' + errorMessage(error), 'error');
+    }
+  }, [showToast]);
 
   const confirmDeleteService = useCallback(async () => {
     if (!serviceToDelete) return;
     
     try {
-      await DeleteService(serviceToDelete.id);
-      showToast('Success', 'Service deleted successfully');
+      if (purgeOnDelete) {
+        const report = await DeleteServiceAndData(serviceToDelete.id, false, serviceToDelete.token);
+        const cleaned = report.files.length + report.registryKeys.length;
+        showToast('Success', `Service deleted successfully, ${cleaned} leftover item(s) removed`);
+      } else {
+        await DeleteService(serviceToDelete.id, false, serviceToDelete.token);
+        showToast('Success', 'Service deleted successfully');
+      }
       loadServices();
     } catch (error) {
-      showToast('Error', 'Failed to delete service: ' + error, 'error');
+      showToast('Error', 'Failed to delete service: ' + errorMessage(error), 'error');
     } finally {
       setIsDeleteDialogOpen(false);
       setServiceToDelete(null);
+      setPurgeOnDelete(false);
     }
-  }, [serviceToDelete, showToast, loadServices]);
+  }, [serviceToDelete, purgeOnDelete, showToast, loadServices]);
 
   const handleMonitorService = useCallback(async (serviceId, serviceStatus) => {
     try {
@@ -251,7 +335,7 @@ function App() {
       await StartMonitoringService(serviceId);
       setMonitoredService(serviceId);
     } catch (error) {
-      showToast('Error', 'Failed to start monitoring service logs: ' + error, 'error');
+      showToast('Error', 'Failed to start monitoring service logs: ' + errorMessage(error), 'error');
     }
   }, [showToast, loadServices]);
 
@@ -261,7 +345,7 @@ function App() {
       showToast('Success', enabled ? 'Auto-start enabled' : 'Auto-start disabled');
       loadServices();
     } catch (error) {
-      showToast('Error', 'Failed to set auto-start: ' + error, 'error');
+      showToast('Error', 'Failed to set auto-start: ' + errorMessage(error), 'error');
     }
   }, [showToast, loadServices]);
 
@@ -272,7 +356,7 @@ function App() {
         setNewService(prev => ({ ...prev, exePath: filePath }));
       }
     } catch (error) {
-      showToast('Error', 'Failed to select file: ' + error, 'error');
+      showToast('Error', 'Failed to select file: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
@@ -283,7 +367,7 @@ function App() {
         setNewService(prev => ({ ...prev, workingDir: dirPath }));
       }
     } catch (error) {
-      showToast('Error', 'Failed to select directory: ' + error, 'error');
+      showToast('Error', 'Failed to select directory: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
@@ -294,7 +378,7 @@ function App() {
         setEnvPath(filePath);
       }
     } catch (error) {
-      showToast('Error', 'Failed to select file: ' + error, 'error');
+      showToast('Error', 'Failed to select file: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
@@ -305,7 +389,7 @@ function App() {
         setEnvPath(dirPath);
       }
     } catch (error) {
-      showToast('Error', 'Failed to select directory: ' + error, 'error');
+      showToast('Error', 'Failed to select directory: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
@@ -335,9 +419,10 @@ function App() {
       console.error('Failed to add environment variable:', error);
       
       // If it's a permission error, perform diagnostics
-      if (error.toString().includes('Access is denied') || 
-          error.toString().includes('access denied') ||
-          error.toString().includes('cannot read existing PATH variable')) {
+      if (error?.code === 'ACCESS_DENIED' ||
+          errorMessage(error).includes('Access is denied') || 
+          errorMessage(error).includes('access denied') ||
+          errorMessage(error).includes('cannot read existing PATH variable')) {
         
         try {
           const diagnosis = await DiagnoseEnvironmentAccess();
@@ -362,10 +447,10 @@ function App() {
           
           showToast('Permission Diagnosis', errorMsg, 'error');
         } catch (diagError) {
-          showToast('Error', 'Failed to add environment variable: ' + error + '\nDiagnosis failed: ' + diagError, 'error');
+          showToast('Error', 'Failed to add environment variable: ' + errorMessage(error) + '\nDiagnosis failed: ' + errorMessage(diagError), 'error');
         }
       } else {
-        showToast('Error', 'Failed to add environment variable: ' + error, 'error');
+        showToast('Error', 'Failed to add environment variable: ' + errorMessage(error), 'error');
       }
     } finally {
       setIsAddingEnv(false);
@@ -376,7 +461,7 @@ function App() {
     try {
       await OpenSystemEnvironmentSettings();
     } catch (error) {
-      showToast('Error', 'Failed to open system environment settings: ' + error, 'error');
+      showToast('Error', 'Failed to open system environment settings: ' + errorMessage(error), 'error');
     }
   }, [showToast]);
 
@@ -440,6 +525,15 @@ function App() {
                           className="win11-input"
                         />
                       </Field>
+
+                      <Field label="Description">
+                        <Input
+                          value={newService.description}
+                          onChange={(e) => setNewService(prev => ({ ...prev, description: e.target.value }))}
+                          placeholder="Shown in Windows Services (optional)"
+                          className="win11-input"
+                        />
+                      </Field>
                       
                       <Field label="Executable Path" required>
                         <div style={{ display: 'flex', gap: '8px' }}>
@@ -506,6 +600,9 @@ function App() {
                     <DialogTrigger disableButtonEnhancement>
                       <Button appearance="secondary" className="win11-button">Cancel</Button>
                     </DialogTrigger>
+                    <Button appearance="secondary" onClick={handleTestRun} className="win11-button">
+                      Test Run
+                    </Button>
                     <Button appearance="primary" onClick={handleCreateService} className="win11-button">
                       Create Service
                     </Button>
@@ -601,6 +698,24 @@ function App() {
                     </div>
                   </Field>
 
+                  <Field>
+                    <div style={{ 
+                      display: 'flex', 
+                      justifyContent: 'space-between', 
+                      alignItems: 'center',
+                      padding: '12px 16px',
+                      backgroundColor: 'rgba(255, 255, 255, 0.5)',
+                      borderRadius: '12px',
+                      backdropFilter: 'blur(10px)'
+                    }}>
+                      <Text>Notify when a service stops unexpectedly</Text>
+                      <Switch
+                        checked={notifyOnCrash}
+                        onChange={(_, data) => handleNotifyOnCrashToggle(data.checked)}
+                      />
+                    </div>
+                  </Field>
+
                   <Field>
                     <div style={{ 
                       display: 'flex', 
@@ -775,14 +890,24 @@ function App() {
               marginBottom: '16px'
             }}>
               <Text size="300" weight="semibold">Service List</Text>
-              <Button 
-                appearance="subtle" 
-                icon={<ArrowClockwise24Regular />}
-                onClick={loadServices}
-                className="win11-button"
-              >
-                Refresh
-              </Button>
+              <div style={{ display: 'flex', gap: '8px' }}>
+                <Button 
+                  appearance="subtle" 
+                  onClick={handleStopAll}
+                  disabled={services.length === 0}
+                  className="win11-button"
+                >
+                  Stop All
+                </Button>
+                <Button 
+                  appearance="subtle" 
+                  icon={<ArrowClockwise24Regular />}
+                  onClick={loadServices}
+                  className="win11-button"
+                >
+                  Refresh
+                </Button>
+              </div>
             </div>
             
             {services.length === 0 ? (
@@ -845,6 +970,29 @@ function App() {
               <Text style={{ marginTop: '8px', color: '#d13438' }}>
                 This service will be permanently removed!
               </Text>
+              {serviceToDelete?.status && serviceToDelete.status !== 'stopped' && (
+                <>
+                  <br />
+                  <Text style={{ marginTop: '8px' }}>
+                    The service is {serviceToDelete.status} and will be stopped first.
+                  </Text>
+                </>
+              )}
+              {serviceToDelete?.logPath && (
+                <>
+                  <br />
+                  <Text style={{ marginTop: '8px' }}>
+                    Log file: {serviceToDelete.logPath}
+                  </Text>
+                </>
+              )}
+              <div style={{ display: 'flex', alignItems: 'center', gap: '8px', marginTop: '8px' }}>
+                <Switch
+                  checked={purgeOnDelete}
+                  onChange={(_, data) => setPurgeOnDelete(data.checked)}
+                />
+                <Text>Also delete log files and leftover registry entries</Text>
+              </div>
             </DialogContent>
             <DialogActions>
               <Button 
@@ -28,9 +28,7 @@ const ServiceLogViewer = ({ serviceId, onClose }) => {
 
     useEffect(() => {
         const handleLogLine = (data) => {
-            if (data.serviceId === serviceId) {
-                setLines(prev => [...prev, data.line]);
-            }
+            setLines(prev => [...prev, data.line]);
         };
 
         const fetchInitialLogs = async () => {
@@ -44,7 +42,7 @@ const ServiceLogViewer = ({ serviceId, onClose }) => {
         };
 
         fetchInitialLogs();
-        const removeListener = window.runtime.EventsOn('service-log-line', handleLogLine);
+        const removeListener = window.runtime.EventsOn(`service-log-line:${serviceId}`, handleLogLine);
         window.go.main.App.StartMonitoringService(serviceId).catch(console.error);
 
         return () => {
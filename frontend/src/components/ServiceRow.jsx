@@ -29,6 +29,7 @@ const ServiceRow = memo(({ service, onStart, onStop, onDelete, onMonitor, onAuto
           }}></div>
           {service.status === 'running' ? 'Running' : 
            service.status === 'error' ? 'Error' : 'Stopped'}
+          {service.status !== 'running' && service.lastExitCode != null && ` (exit code ${service.lastExitCode})`}
         </div>
       </TableCell>
       <TableCell>
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Health check types supported in ServiceConfig.HealthCheckType
+const (
+	healthCheckTCP  = "tcp"
+	healthCheckHTTP = "http"
+)
+
+// Health states reported in Service.Health
+const (
+	healthHealthy   = "healthy"
+	healthUnhealthy = "unhealthy"
+)
+
+// healthCheckTick is how often the health checker looks for services that are due for a probe
+const healthCheckTick = 5 * time.Second
+
+// defaultHealthCheckIntervalSec is how often a service is probed when it does not set an interval
+const defaultHealthCheckIntervalSec = 30
+
+// healthCheckTimeout bounds a single probe
+const healthCheckTimeout = 5 * time.Second
+
+// healthCheck is the probe configuration of one running service
+type healthCheck struct {
+	serviceID string
+	kind      string
+	target    string
+}
+
+// validateHealthCheck checks the health check settings of a service configuration
+func validateHealthCheck(kind, target string, intervalSec int) error {
+	if intervalSec < 0 {
+		return fmt.Errorf("health check interval must not be negative")
+	}
+
+	switch kind {
+	case "":
+		return nil
+	case healthCheckTCP:
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			return fmt.Errorf("health check target must be host:port: %v", err)
+		}
+	case healthCheckHTTP:
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			return fmt.Errorf("health check target must be an http:// or https:// URL")
+		}
+	default:
+		return fmt.Errorf("unknown health check type: %s (use %q or %q)", kind, healthCheckTCP, healthCheckHTTP)
+	}
+	return nil
+}
+
+// StartHealthChecker probes running services that have a health check configured and emits
+// service-health-changed whenever a service turns healthy or unhealthy
+func (wsm *WindowsServiceManager) StartHealthChecker() {
+	wsm.StopHealthChecker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	wsm.healthMutex.Lock()
+	wsm.healthCancel = cancel
+	wsm.healthDone = done
+	wsm.healthMutex.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(healthCheckTick)
+		defer ticker.Stop()
+
+		lastChecked := make(map[string]time.Time)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				wsm.checkServiceHealth(ctx, lastChecked)
+			}
+		}
+	}()
+}
+
+// StopHealthChecker stops the background health checker and waits for it to exit
+func (wsm *WindowsServiceManager) StopHealthChecker() {
+	wsm.healthMutex.Lock()
+	cancel, done := wsm.healthCancel, wsm.healthDone
+	wsm.healthCancel, wsm.healthDone = nil, nil
+	wsm.healthMutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// checkServiceHealth probes every running service whose interval has elapsed.
+// Probes run without the lock so a slow target does not block other operations.
+func (wsm *WindowsServiceManager) checkServiceHealth(ctx context.Context, lastChecked map[string]time.Time) {
+	for _, check := range wsm.dueHealthChecks(lastChecked) {
+		if ctx.Err() != nil {
+			return
+		}
+		lastChecked[check.serviceID] = time.Now()
+
+		health := healthHealthy
+		if err := probeHealth(ctx, check.kind, check.target); err != nil {
+			health = healthUnhealthy
+		}
+		wsm.setServiceHealth(check.serviceID, health)
+	}
+}
+
+// dueHealthChecks returns the health checks of running services that are due for a probe.
+// Services that stopped or lost their health check have their health cleared.
+func (wsm *WindowsServiceManager) dueHealthChecks(lastChecked map[string]time.Time) []healthCheck {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	var checks []healthCheck
+	for _, service := range wsm.services {
+		values := readServiceParameters(service.ID, "HealthCheckType", "HealthCheckTarget", "HealthCheckIntervalSec")
+		kind := values["HealthCheckType"]
+
+		if kind == "" || service.Status != "running" {
+			if service.Health != "" {
+				service.Health = ""
+				wsm.emitServiceHealthChanged(service.ID, "")
+			}
+			delete(lastChecked, service.ID)
+			continue
+		}
+
+		intervalSec, err := strconv.Atoi(values["HealthCheckIntervalSec"])
+		if err != nil || intervalSec <= 0 {
+			intervalSec = defaultHealthCheckIntervalSec
+		}
+		if time.Since(lastChecked[service.ID]) < time.Duration(intervalSec)*time.Second {
+			continue
+		}
+
+		checks = append(checks, healthCheck{
+			serviceID: service.ID,
+			kind:      kind,
+			target:    values["HealthCheckTarget"],
+		})
+	}
+	return checks
+}
+
+// setServiceHealth records the health of a service and emits an event when it changed
+func (wsm *WindowsServiceManager) setServiceHealth(serviceID, health string) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists || service.Health == health {
+		return
+	}
+	service.Health = health
+	wsm.emitServiceHealthChanged(serviceID, health)
+}
+
+// emitServiceHealthChanged tells the frontend that a service's health changed
+func (wsm *WindowsServiceManager) emitServiceHealthChanged(serviceID, health string) {
+	if wsm.ctx != nil {
+		runtime.EventsEmit(wsm.ctx, "service-health-changed", map[string]interface{}{
+			"serviceId": serviceID,
+			"health":    health,
+		})
+	}
+}
+
+// probeHealth runs one TCP or HTTP probe and returns why it failed, if it did
+func probeHealth(ctx context.Context, kind, target string) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	switch kind {
+	case healthCheckTCP:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case healthCheckHTTP:
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		if response.StatusCode >= 400 {
+			return fmt.Errorf("health check returned %s", response.Status)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown health check type: %s", kind)
+	}
+}
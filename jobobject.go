@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// newKillOnCloseJob creates a Job Object that terminates all its processes when its handle is closed
+// and assigns the process pid to it. Children started by the process afterwards join the job too.
+func newKillOnCloseJob(pid int) (windows.Handle, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create job object: %v", err)
+	}
+
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		windows.CloseHandle(job)
+		return 0, fmt.Errorf("failed to configure job object: %v", err)
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		windows.CloseHandle(job)
+		return 0, fmt.Errorf("failed to open process %d: %v", pid, err)
+	}
+	defer windows.CloseHandle(process)
+
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		windows.CloseHandle(job)
+		return 0, fmt.Errorf("failed to assign process %d to job object: %v", pid, err)
+	}
+
+	return job, nil
+}
+
+// closeJob closes the job of the target, which terminates whatever is still running in it
+func (esw *EmbeddedServiceWrapper) closeJob() {
+	if esw.job == 0 {
+		return
+	}
+	if err := windows.CloseHandle(esw.job); err != nil {
+		log.Printf("Failed to close job object: %v", err)
+	}
+	esw.job = 0
+}
+
+// killDescendants terminates the processes of a tree captured by processTree, except its root.
+// It is the fallback for targets that could not be placed in a job.
+func killDescendants(tree []ProcessInfo) {
+	// Children are listed after their parents, so go backwards to stop the leaves first
+	for i := len(tree) - 1; i > 0; i-- {
+		if err := terminateProcess(tree[i].PID); err != nil {
+			log.Printf("Failed to terminate child process %s (PID %d): %v", tree[i].Name, tree[i].PID, err)
+			continue
+		}
+		log.Printf("Terminated child process %s (PID %d)", tree[i].Name, tree[i].PID)
+	}
+}
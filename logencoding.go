@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ansiEscape matches ANSI escape sequences: CSI sequences such as colors, OSC sequences such
+// as window titles, and two-byte escapes
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// codePageEncodings maps Windows code page numbers to encoding names known to htmlindex
+var codePageEncodings = map[string]string{
+	"437":   "ibm866", // closest match available for the OEM US code page
+	"866":   "ibm866",
+	"932":   "shift_jis",
+	"936":   "gbk",
+	"949":   "euc-kr",
+	"950":   "big5",
+	"1250":  "windows-1250",
+	"1251":  "windows-1251",
+	"1252":  "windows-1252",
+	"1253":  "windows-1253",
+	"1254":  "windows-1254",
+	"1255":  "windows-1255",
+	"1256":  "windows-1256",
+	"1257":  "windows-1257",
+	"1258":  "windows-1258",
+	"54936": "gb18030",
+	"65001": "utf-8",
+}
+
+// lookupLogEncoding finds the encoding for a LogEncoding name such as "shift_jis", "cp932" or "932".
+// Logs are split into lines byte by byte, so UTF-16 is not supported.
+func lookupLogEncoding(name string) (encoding.Encoding, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if mapped, ok := codePageEncodings[strings.TrimPrefix(key, "cp")]; ok {
+		key = mapped
+	}
+
+	enc, err := htmlindex.Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("unknown log encoding: %s", name)
+	}
+	if strings.HasPrefix(key, "utf-16") {
+		return nil, fmt.Errorf("log encoding %s is not supported, logs must use a single-byte or multi-byte encoding", name)
+	}
+	return enc, nil
+}
+
+// validateLogEncoding checks a LogEncoding setting; empty means the log is UTF-8
+func validateLogEncoding(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+	_, err := lookupLogEncoding(name)
+	return err
+}
+
+// newLogLineDecoder returns a function that turns a raw log line into displayable UTF-8 text.
+// The line is transcoded from encodingName (passed through when empty or unknown) and
+// ANSI escape sequences are removed, since the log panel cannot render them.
+func newLogLineDecoder(encodingName string) func(string) string {
+	var decoder *encoding.Decoder
+	if strings.TrimSpace(encodingName) != "" {
+		if enc, err := lookupLogEncoding(encodingName); err == nil && enc != unicode.UTF8 {
+			decoder = enc.NewDecoder()
+		}
+	}
+
+	return func(line string) string {
+		if decoder != nil {
+			if decoded, err := decoder.String(line); err == nil {
+				line = decoded
+			}
+		}
+		if strings.IndexByte(line, 0x1b) >= 0 {
+			line = ansiEscape.ReplaceAllString(line, "")
+		}
+		return line
+	}
+}
+
+// logLineDecoder returns the line decoder for the log encoding configured for a service
+func (a *App) logLineDecoder(serviceID string) func(string) string {
+	return newLogLineDecoder(readServiceParameters(serviceID, "LogEncoding")["LogEncoding"])
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+// Log rotation defaults used when a service does not configure its own limits
+const (
+	defaultLogMaxSizeMB  = 10
+	defaultLogMaxBackups = 3
+)
+
+// logNoBackups is how the registry stores a LogMaxBackups of 0. Earlier versions stored "0"
+// for an unset value, so a stored "0" keeps meaning the default.
+const logNoBackups = "none"
+
+// formatLogMaxBackups encodes a LogMaxBackups setting for the registry
+func formatLogMaxBackups(n int) string {
+	if n == 0 {
+		return logNoBackups
+	}
+	return strconv.Itoa(n)
+}
+
+// parseLogMaxBackups decodes a LogMaxBackups registry value, nil when it is unset
+func parseLogMaxBackups(value string) *int {
+	if value == logNoBackups {
+		none := 0
+		return &none
+	}
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return &n
+	}
+	return nil
+}
+
+// resolveLogMaxBackups returns the number of backups to keep, the default when unset
+func resolveLogMaxBackups(maxBackups *int) int {
+	if maxBackups == nil {
+		return defaultLogMaxBackups
+	}
+	return *maxBackups
+}
+
+// rotatingWriter appends to a log file and rotates it once it grows past maxSize.
+// Rotated files are named <name>.1.log (newest) up to <name>.<maxBackups>.log (oldest);
+// with maxBackups 0 the file is emptied instead.
+type rotatingWriter struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+
+	// onDiskFull is called once when a write fails because the disk is full;
+	// output is dropped until a write succeeds again
+	onDiskFull func(err error)
+	diskFull   bool
+}
+
+// openRotatingWriter opens path for appending, keeping any existing content
+func openRotatingWriter(path string, maxSizeMB, maxBackups int) (*rotatingWriter, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultLogMaxSizeMB
+	}
+	if maxBackups < 0 {
+		return nil, fmt.Errorf("log backups must not be negative")
+	}
+
+	rw := &rotatingWriter{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := rw.open(); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// open opens the current log file in append mode and records its size
+func (rw *rotatingWriter) open() error {
+	file, err := os.OpenFile(rw.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	rw.file = file
+	rw.size = info.Size()
+	return nil
+}
+
+func (rw *rotatingWriter) Write(p []byte) (int, error) {
+	rw.mutex.Lock()
+
+	if rw.file == nil {
+		rw.mutex.Unlock()
+		return 0, os.ErrClosed
+	}
+
+	if rw.size > 0 && rw.size+int64(len(p)) > rw.maxSize {
+		if err := rw.rotate(); err != nil {
+			// Keep logging to the oversized file rather than losing output
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+			if rw.file == nil {
+				rw.mutex.Unlock()
+				return 0, err
+			}
+		}
+	}
+
+	n, err := rw.file.Write(p)
+	rw.size += int64(n)
+
+	if err != nil && isDiskFullError(err) {
+		// Failing the write would break the target's output pipe, so drop the output instead
+		firstDrop := !rw.diskFull
+		rw.diskFull = true
+		rw.mutex.Unlock()
+
+		if firstDrop && rw.onDiskFull != nil {
+			rw.onDiskFull(err)
+		}
+		return len(p), nil
+	}
+	if err == nil {
+		rw.diskFull = false
+	}
+
+	rw.mutex.Unlock()
+	return n, err
+}
+
+// isDiskFullError reports whether err means the disk has no space left
+func isDiskFullError(err error) bool {
+	return errors.Is(err, windows.ERROR_DISK_FULL) || errors.Is(err, windows.ERROR_HANDLE_DISK_FULL)
+}
+
+// backupPath returns the name of the n-th rotated file
+func (rw *rotatingWriter) backupPath(n int) string {
+	ext := filepath.Ext(rw.path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(rw.path, ext), n, ext)
+}
+
+// rotate shifts the backups up by one and starts a new, empty log file.
+// If the current file cannot be renamed (e.g. a reader holds it open), its content is
+// copied to the first backup and the file is truncated instead.
+// When the log cannot be reopened afterwards rw.file is left nil.
+func (rw *rotatingWriter) rotate() error {
+	if rw.maxBackups == 0 {
+		// Writes append, so they continue at the start of the emptied file
+		if err := rw.file.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate log file: %w", err)
+		}
+		rw.size = 0
+		return nil
+	}
+
+	os.Remove(rw.backupPath(rw.maxBackups))
+	for n := rw.maxBackups - 1; n >= 1; n-- {
+		os.Rename(rw.backupPath(n), rw.backupPath(n+1))
+	}
+
+	rw.file.Close()
+	rw.file = nil
+
+	if err := os.Rename(rw.path, rw.backupPath(1)); err != nil {
+		if err := copyFile(rw.path, rw.backupPath(1)); err != nil {
+			return rw.reopenAfter(err)
+		}
+		if err := os.Truncate(rw.path, 0); err != nil {
+			return rw.reopenAfter(fmt.Errorf("failed to truncate log file: %w", err))
+		}
+	}
+
+	return rw.open()
+}
+
+// reopenAfter reopens the current log file after a failed rotation, returning err
+// together with any error reopening it
+func (rw *rotatingWriter) reopenAfter(err error) error {
+	if openErr := rw.open(); openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	return err
+}
+
+// Close closes the current log file
+func (rw *rotatingWriter) Close() error {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	if rw.file == nil {
+		return nil
+	}
+	err := rw.file.Close()
+	rw.file = nil
+	return err
+}
+
+// logBackupFiles returns the rotated backups of logPath that exist, named <base>.<n><ext>
+func logBackupFiles(logPath string) []string {
+	ext := filepath.Ext(logPath)
+	base := strings.TrimSuffix(logPath, ext)
+
+	matches, err := filepath.Glob(base + ".*" + ext)
+	if err != nil {
+		return nil
+	}
+
+	var backups []string
+	for _, match := range matches {
+		n := strings.TrimSuffix(strings.TrimPrefix(match, base+"."), ext)
+		if _, err := strconv.Atoi(n); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	return backups
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeRotating opens a writer with the given backups and a 1 MB limit, then writes
+// three chunks that each push the log past the limit
+func writeRotating(t *testing.T, path string, maxBackups int) {
+	t.Helper()
+
+	rw, err := openRotatingWriter(path, 1, maxBackups)
+	if err != nil {
+		t.Fatalf("openRotatingWriter: %v", err)
+	}
+	defer rw.Close()
+
+	for _, fill := range []string{"a", "b", "c"} {
+		chunk := strings.Repeat(fill, 700*1024)
+		if _, err := rw.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+}
+
+func TestRotatingWriterKeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeRotating(t, path, 1)
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+	if len(current) == 0 || current[0] != 'c' {
+		t.Errorf("current log does not start with the last chunk")
+	}
+
+	backup, err := os.ReadFile(filepath.Join(filepath.Dir(path), "app.1.log"))
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	if len(backup) == 0 || backup[0] != 'b' {
+		t.Errorf("backup does not hold the previous chunk")
+	}
+
+	if backups := logBackupFiles(path); len(backups) != 1 {
+		t.Errorf("backups = %v, want only app.1.log", backups)
+	}
+}
+
+func TestRotatingWriterWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeRotating(t, path, 0)
+
+	if backups := logBackupFiles(path); len(backups) != 0 {
+		t.Errorf("backups = %v, want none", backups)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+	if len(current) != 700*1024 || current[0] != 'c' {
+		t.Errorf("log holds %d bytes, want only the last chunk", len(current))
+	}
+}
+
+func TestRotatingWriterRejectsNegativeBackups(t *testing.T) {
+	if _, err := openRotatingWriter(filepath.Join(t.TempDir(), "app.log"), 1, -1); err == nil {
+		t.Errorf("openRotatingWriter accepted negative backups")
+	}
+}
+
+func TestLogMaxBackupsRegistryValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"none", 0},
+		{"0", defaultLogMaxBackups}, // written for an unset value by earlier versions
+		{"", defaultLogMaxBackups},
+		{"junk", defaultLogMaxBackups},
+		{"5", 5},
+	}
+
+	for _, tt := range tests {
+		if got := resolveLogMaxBackups(parseLogMaxBackups(tt.value)); got != tt.want {
+			t.Errorf("LogMaxBackups %q resolves to %d, want %d", tt.value, got, tt.want)
+		}
+	}
+
+	for _, n := range []int{0, 1, 5} {
+		if got := resolveLogMaxBackups(parseLogMaxBackups(formatLogMaxBackups(n))); got != n {
+			t.Errorf("LogMaxBackups %d round-trips to %d", n, got)
+		}
+	}
+}
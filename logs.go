@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// defaultLogTimestampPattern matches a leading timestamp such as
+// "2024-01-02 15:04:05", "[2024/01/02 15:04:05.123]" or "2024-01-02T15:04:05Z"
+var defaultLogTimestampPattern = regexp.MustCompile(`^\[?(\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\]?`)
+
+// logTimestampLayouts are tried in order against a normalized timestamp
+var logTimestampLayouts = []string{
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05",
+}
+
+// parseLogTimestamp extracts the leading timestamp of a log line using pattern.
+// The first capture group is used when present, otherwise the whole match.
+func parseLogTimestamp(pattern *regexp.Regexp, line string) (time.Time, bool) {
+	match := pattern.FindStringSubmatch(line)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	raw := match[0]
+	if len(match) > 1 && match[1] != "" {
+		raw = match[1]
+	}
+
+	normalized := strings.NewReplacer("/", "-", ",", ".", "T", " ").Replace(strings.Trim(raw, "[]"))
+	for _, layout := range logTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, normalized, time.Local); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// filterLogLinesByTime keeps lines whose timestamp falls within [from, to].
+// Lines without a timestamp are kept only when they sit between two in-range lines.
+func filterLogLinesByTime(pattern *regexp.Regexp, lines []string, from, to time.Time) []string {
+	var selected, pending []string
+	inRange := false
+
+	for _, line := range lines {
+		t, ok := parseLogTimestamp(pattern, line)
+		if !ok {
+			if inRange {
+				pending = append(pending, line)
+			}
+			continue
+		}
+
+		if t.Before(from) || t.After(to) {
+			inRange = false
+			pending = nil
+			continue
+		}
+
+		if inRange {
+			selected = append(selected, pending...)
+		}
+		pending = nil
+		selected = append(selected, line)
+		inRange = true
+	}
+
+	return selected
+}
+
+// logLevelField matches an explicit level field such as "level=warn" or "level: ERROR"
+var logLevelField = regexp.MustCompile(`(?i)\blevel["']?\s*[=:]\s*["']?([a-z]+)`)
+
+// logLevelToken matches a conventional upper-case level word such as "[WARN]" or "ERROR:"
+var logLevelToken = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|ERR|FATAL|CRITICAL)\b`)
+
+// normalizeLogLevel maps level spellings to "debug", "info", "warn" or "error"
+func normalizeLogLevel(level string) string {
+	switch strings.ToLower(level) {
+	case "trace", "debug":
+		return "debug"
+	case "info", "information", "notice":
+		return "info"
+	case "warn", "warning":
+		return "warn"
+	case "err", "error", "fatal", "critical", "crit", "panic":
+		return "error"
+	default:
+		return ""
+	}
+}
+
+// detectLogLevel guesses the level of a log line, returning an empty string when it has none
+func detectLogLevel(line string) string {
+	if match := logLevelField.FindStringSubmatch(line); match != nil {
+		if level := normalizeLogLevel(match[1]); level != "" {
+			return level
+		}
+	}
+	if match := logLevelToken.FindStringSubmatch(line); match != nil {
+		return normalizeLogLevel(match[1])
+	}
+	return ""
+}
+
+// logLineEvent builds the service-log-line payload: the raw line plus its detected level and
+// leading timestamp, each left out when the line does not have one
+func (a *App) logLineEvent(serviceID, line string) map[string]interface{} {
+	event := map[string]interface{}{
+		"serviceId": serviceID,
+		"line":      line,
+	}
+	if level := detectLogLevel(line); level != "" {
+		event["level"] = level
+	}
+	if t, ok := parseLogTimestamp(a.timestampPattern(), line); ok {
+		event["timestamp"] = t
+	}
+	return event
+}
+
+// serviceLogLineEvent is the event a single service's log lines are emitted on,
+// so a panel showing one service does not receive the lines of all others
+func serviceLogLineEvent(serviceID string) string {
+	return "service-log-line:" + serviceID
+}
+
+// emitLogLine sends a tailed log line on the service's own channel and, unless turned off
+// in the settings, on the shared service-log-line event as well
+func (a *App) emitLogLine(serviceID, line string) {
+	event := a.logLineEvent(serviceID, line)
+	runtime.EventsEmit(a.ctx, serviceLogLineEvent(serviceID), event)
+	if !a.currentSettings().DisableGenericLogEvents {
+		runtime.EventsEmit(a.ctx, "service-log-line", event)
+	}
+}
+
+// SetLogTimestampPattern sets the regular expression used to find timestamps in log lines.
+// An empty pattern restores the built-in detection.
+func (a *App) SetLogTimestampPattern(pattern string) error {
+	if pattern == "" {
+		a.logTimestampPattern.Store(defaultLogTimestampPattern)
+		return nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp pattern: %v", err)
+	}
+	a.logTimestampPattern.Store(compiled)
+	return nil
+}
+
+// timestampPattern returns the regular expression used to find timestamps in log lines
+func (a *App) timestampPattern() *regexp.Regexp {
+	if pattern := a.logTimestampPattern.Load(); pattern != nil {
+		return pattern
+	}
+	return defaultLogTimestampPattern
+}
+
+// ExportLogRange writes the log lines of a service between from and to into a user-chosen file.
+// It returns the written path, or an empty string if the dialog was cancelled.
+func (a *App) ExportLogRange(serviceID string, from, to time.Time) (string, error) {
+	if to.Before(from) {
+		return "", fmt.Errorf("end of range is before its start")
+	}
+
+	logPath, _, err := a.serviceManager.GetServiceLogPath(serviceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get log path: %v", err)
+	}
+
+	lines, err := a.readAllLines(logPath, a.logLineDecoder(serviceID))
+	if err != nil {
+		return "", fmt.Errorf("failed to read log file: %v", err)
+	}
+
+	selected := filterLogLinesByTime(a.timestampPattern(), lines, from, to)
+
+	target, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Log Range",
+		DefaultFilename: fmt.Sprintf("%s_%s.log", serviceID, from.Format("20060102-150405")),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "Log Files (*.log)",
+				Pattern:     "*.log",
+			},
+		},
+	})
+	if err != nil || target == "" {
+		return "", err
+	}
+
+	content := strings.Join(selected, "\r\n")
+	if len(selected) > 0 {
+		content += "\r\n"
+	}
+	if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write export file: %v", err)
+	}
+
+	return target, nil
+}
+
+// readLastLines returns up to n lines that end before offset end, reading backwards from end
+// in chunks so large logs are never read in full
+func readLastLines(file io.ReaderAt, end int64, n int) ([]string, error) {
+	const chunkSize = 8192
+
+	var data []byte
+	offset := end
+	for offset > 0 && bytes.Count(data, []byte{'\n'}) <= n {
+		size := int64(chunkSize)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+
+		chunk := make([]byte, size)
+		read, err := file.ReadAt(chunk, offset)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		// The file may have been truncated since end was taken, keep only what was read
+		data = append(chunk[:read], data...)
+	}
+
+	text := strings.TrimRight(string(data), "\r\n")
+	if text == "" {
+		return nil, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	// The first line is partial unless we reached the start of the file
+	if offset > 0 && len(lines) > n {
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+	return lines, nil
+}
+
+// LogMatch is a log line returned by SearchLogContent
+type LogMatch struct {
+	Line int    `json:"line"` // 1-based line number
+	Text string `json:"text"`
+}
+
+// compileLogQuery turns a search query into a line matcher.
+// A query wrapped in slashes ("/error \d+/") is a regular expression, anything else a substring.
+func compileLogQuery(query string, caseSensitive bool) (func(string) bool, error) {
+	if len(query) >= 2 && strings.HasPrefix(query, "/") && strings.HasSuffix(query, "/") {
+		expr := query[1 : len(query)-1]
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		return pattern.MatchString, nil
+	}
+
+	if caseSensitive {
+		return func(line string) bool {
+			return strings.Contains(line, query)
+		}, nil
+	}
+	lowered := strings.ToLower(query)
+	return func(line string) bool {
+		return strings.Contains(strings.ToLower(line), lowered)
+	}, nil
+}
+
+// SearchLogContent returns the log lines of a service that match query, without loading the whole file.
+// At most maxResults matches are returned; maxResults <= 0 means no limit.
+func (a *App) SearchLogContent(serviceID string, query string, caseSensitive bool, maxResults int) ([]LogMatch, error) {
+	match, err := compileLogQuery(query, caseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	logPath, _, err := a.serviceManager.GetServiceLogPath(serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log path: %v", err)
+	}
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	decode := a.logLineDecoder(serviceID)
+
+	matches := make([]LogMatch, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := decode(scanner.Text())
+		if !match(line) {
+			continue
+		}
+		matches = append(matches, LogMatch{Line: lineNumber, Text: line})
+		if maxResults > 0 && len(matches) >= maxResults {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return matches, fmt.Errorf("failed to read log file: %v", err)
+	}
+	return matches, nil
+}
+
+// ClearServiceLog truncates the log file of a service and tells the frontend to clear its buffer.
+// An active tailer is stopped for the truncation and started again afterwards.
+func (a *App) ClearServiceLog(serviceID string) (err error) {
+	logPath, _, err := a.serviceManager.GetServiceLogPath(serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to get log path: %v", err)
+	}
+
+	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		return fmt.Errorf("log file does not exist yet: %s", logPath)
+	}
+
+	a.logTailersLock.Lock()
+	_, monitoring := a.logTailers[serviceID]
+	a.logTailersLock.Unlock()
+
+	if monitoring {
+		a.StopMonitoringService(serviceID)
+		// Resume tailing even if the log could not be cleared, so the viewer keeps updating
+		defer func() {
+			if restartErr := a.StartMonitoringService(serviceID); restartErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to resume log monitoring: %w", restartErr))
+			}
+		}()
+	}
+
+	if err := os.Truncate(logPath, 0); err != nil {
+		return fmt.Errorf("failed to clear log file: %v", err)
+	}
+
+	runtime.EventsEmit(a.ctx, "service-log-cleared", map[string]interface{}{
+		"serviceId": serviceID,
+	})
+
+	return nil
+}
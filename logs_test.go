@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReadLastLines(t *testing.T) {
+	long := strings.Repeat("x", 20000)
+
+	tests := []struct {
+		name    string
+		content string
+		n       int
+		want    []string
+	}{
+		{"empty file", "", 5, nil},
+		{"fewer lines than asked", "one\ntwo\n", 5, []string{"one", "two"}},
+		{"last lines only", "one\ntwo\nthree\nfour\n", 2, []string{"three", "four"}},
+		{"partial last line", "one\ntwo\nthr", 2, []string{"two", "thr"}},
+		{"no newline at all", "only a partial line", 3, []string{"only a partial line"}},
+		{"crlf line endings", "one\r\ntwo\r\nthree\r\n", 2, []string{"two", "three"}},
+		{"blank lines kept", "one\n\ntwo\n", 3, []string{"one", "", "two"}},
+		{"line longer than a chunk", "first\n" + long + "\nlast\n", 2, []string{long, "last"}},
+		{"many chunks", strings.Repeat(strings.Repeat("y", 99)+"\n", 1000) + "end\n", 1, []string{"end"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readLastLines(strings.NewReader(tt.content), int64(len(tt.content)), tt.n)
+			if err != nil {
+				t.Fatalf("readLastLines: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readLastLines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLastLinesStopsAtEnd(t *testing.T) {
+	content := "one\ntwo\nthree\nlater output\n"
+	end := int64(strings.Index(content, "later"))
+
+	got, err := readLastLines(strings.NewReader(content), end, 2)
+	if err != nil {
+		t.Fatalf("readLastLines: %v", err)
+	}
+	if want := []string{"two", "three"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readLastLines = %q, want %q", got, want)
+	}
+}
+
+func TestReadLastLinesTruncatedFile(t *testing.T) {
+	// The log was truncated (rotated) after its size was taken
+	content := "fresh\noutput\n"
+
+	got, err := readLastLines(strings.NewReader(content), 4096, 5)
+	if err != nil {
+		t.Fatalf("readLastLines: %v", err)
+	}
+	if want := []string{"fresh", "output"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readLastLines = %q, want %q", got, want)
+	}
+
+	got, err = readLastLines(strings.NewReader(""), 4096, 5)
+	if err != nil || got != nil {
+		t.Errorf("readLastLines of an emptied file = %q, %v, want nothing", got, err)
+	}
+}
+
+// TestLogTimestampPatternChangedWhileTailing swaps the pattern while other goroutines parse lines
+// the way tailers do. It is meant to be run with -race.
+func TestLogTimestampPatternChangedWhileTailing(t *testing.T) {
+	app := newTestApp(t)
+	line := "2024-05-01 12:30:00 started"
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				app.logLineEvent("WSM_app", line)
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		pattern := `^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`
+		if i%2 == 0 {
+			pattern = ""
+		}
+		if err := app.SetLogTimestampPattern(pattern); err != nil {
+			t.Fatalf("SetLogTimestampPattern: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if _, ok := app.logLineEvent("WSM_app", line)["timestamp"]; !ok {
+		t.Errorf("no timestamp found with the last pattern set")
+	}
+}
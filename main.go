@@ -34,6 +34,14 @@ func main() {
 		return
 	}
 
+	// Headless command line mode
+	if handled, exitCode := runCLI(os.Args[1:]); handled {
+		os.Exit(exitCode)
+	}
+
+	// An elevated relaunch waits until the instance that started it releases the single instance lock
+	waitForRelaunchParent(os.Args[1:])
+
 	// Normal GUI mode
 	app := NewApp()
 
@@ -69,12 +77,14 @@ func main() {
 			return true
 		},
 		OnShutdown: func(ctx context.Context) {
+			app.shutdown(ctx)
 			systrayManager.Cleanup()
 			os.Exit(0)
 		},
 		Bind: []interface{}{
 			app,
 		},
+		ErrorFormatter:   formatError,
 		WindowStartState: options.Normal,
 		Windows: &windows.Options{
 			WebviewIsTransparent: false,
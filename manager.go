@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unsafe"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"golang.org/x/sys/windows"
@@ -17,6 +23,9 @@ import (
 	"golang.org/x/sys/windows/svc/mgr"
 )
 
+// managedByMarker is stored in a service's Parameters key to identify services created by this tool
+const managedByMarker = "Windows Service Manager"
+
 // WindowsServiceManager manages services using the Windows Service Control Manager API
 type WindowsServiceManager struct {
 	mutex       sync.RWMutex
@@ -24,44 +33,289 @@ type WindowsServiceManager struct {
 	services    map[string]*Service
 	statusCache *ServiceStatusCache
 	ctx         context.Context
+	scm         scmConnector
+
+	confirmations *confirmationStore
+	namePrefix    string
+	notifyOnCrash bool
+	logDir        string // folder holding per-service log folders, empty for the default
+
+	cpuSamples      map[int]cpuSample // PID -> previous CPU sample for usage deltas
+	cpuSamplesMutex sync.Mutex
+
+	watcherCancel   context.CancelFunc
+	watcherDone     chan struct{}
+	watcherMutex    sync.Mutex
+	watchedStatuses map[string]watchedStatus // status last reported per service, guarded by watcherMutex
+	lowLogVolumes   map[string]bool          // log drives already reported as low on space
+
+	quietStatusEvents bool // set while a bulk operation runs, which emits services-updated once at the end
+
+	healthCancel context.CancelFunc
+	healthDone   chan struct{}
+	healthMutex  sync.Mutex
 }
 
-// NewWindowsServiceManager creates a new Windows service manager
+// NewWindowsServiceManager creates a new Windows service manager.
+// When the config dir is unavailable the data file falls back to fallbackDataPath.
 func NewWindowsServiceManager() *WindowsServiceManager {
 	cache := NewServiceStatusCache()
 	cache.StartCleanupRoutine()
 	path, err := getDataConfigPath()
 	if err != nil {
-		fmt.Printf("Warning: failed to get data config path: %v\n", err)
-		path = "services_data.json"
-		return nil
+		path = fallbackDataPath()
+		fmt.Printf("Warning: failed to get data config path, using %s: %v\n", path, err)
 	}
 
 	return &WindowsServiceManager{
 		services:    make(map[string]*Service),
 		dataFile:    path,
 		statusCache: cache,
+		scm:         mgrConnector{},
+
+		confirmations: newConfirmationStore(),
+		namePrefix:    defaultServiceNamePrefix,
+
+		cpuSamples: make(map[int]cpuSample),
 	}
 }
 
 // getDataConfigPath returns the path to the data config file
 func getDataConfigPath() (string, error) {
-    configDir, err := os.UserConfigDir()
-    if err != nil {
-        return "", err
-    }
-    return filepath.Join(configDir, "Windows Service Manager.exe", "data.json"), nil
+	return configFilePath("data.json")
+}
+
+// fallbackDataPath returns where the data file goes when the config dir is unavailable:
+// next to the executable, or the working directory if even that cannot be determined
+func fallbackDataPath() string {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "services_data.json"
+	}
+	return filepath.Join(filepath.Dir(exePath), "services_data.json")
+}
+
+// SetDataFile switches the file services are persisted to and writes the current services there
+func (wsm *WindowsServiceManager) SetDataFile(path string) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	wsm.dataFile = path
+	wsm.saveServices()
+}
+
+// GetServiceLogPath retrieves the log file path from the registry and reports whether the file exists.
+// Services saved before the log path was persisted fall back to the stored or default path.
+func (wsm *WindowsServiceManager) GetServiceLogPath(serviceID string) (path string, exists bool, err error) {
+	wsm.mutex.RLock()
+	service, found := wsm.services[serviceID]
+	var storedPath, fallbackPath string
+	if found {
+		storedPath = service.LogPath
+		fallbackPath = wsm.defaultLogPath(serviceID)
+	}
+	wsm.mutex.RUnlock()
+
+	if !found {
+		return "", false, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	path = readServiceParameters(serviceID, "StdoutLog")["StdoutLog"]
+	if path == "" {
+		path = storedPath
+	}
+	if path == "" {
+		path = fallbackPath
+	}
+
+	info, err := os.Stat(path)
+	return path, err == nil && !info.IsDir(), nil
+}
+
+// GetServiceErrorLogPath returns the file a service's stderr goes to, or an empty string
+// when stderr is written to the regular log
+func (wsm *WindowsServiceManager) GetServiceErrorLogPath(serviceID string) (string, error) {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	return service.ErrorLogPath, nil
+}
+
+// SetServiceLogPath moves the log of a service to path; the wrapper picks it up at the next start.
+// An empty path goes back to the default location in the log directory.
+func (wsm *WindowsServiceManager) SetServiceLogPath(serviceID, path string) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if service.Adopted {
+		return fmt.Errorf("service %s is not run by the built-in wrapper, its log path cannot be changed", serviceID)
+	}
+
+	logPath := wsm.defaultLogPath(serviceID)
+	if path != "" {
+		resolved, err := resolvePath(path)
+		if err != nil {
+			return err
+		}
+		logPath = resolved
+	}
+	if err := prepareLogDir(logPath); err != nil {
+		return err
+	}
+
+	names := []string{"StdoutLog", "StderrLog"}
+	if service.ErrorLogPath != "" {
+		names = names[:1]
+	}
+	for _, name := range names {
+		if err := wsm.setServiceRegistryValue(serviceID, "Parameters", name, logPath); err != nil {
+			return fmt.Errorf("failed to set log path: %w", err)
+		}
+	}
+
+	service.LogPath = logPath
+	service.UpdatedAt = time.Now()
+	wsm.saveServices()
+	wsm.emitServicesUpdated()
+	return nil
+}
+
+// readServiceImagePath reads the raw ImagePath value exactly as SCM stores it
+func readServiceImagePath(serviceName string) (string, error) {
+	keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Services\%s`, serviceName)
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return "", wrapAccessError("failed to open service registry key", err)
+	}
+	defer k.Close()
+
+	imagePath, _, err := k.GetStringValue("ImagePath")
+	if err != nil {
+		return "", fmt.Errorf("failed to read ImagePath: %v", err)
+	}
+	return imagePath, nil
+}
+
+// GetServiceImagePath returns the command line SCM will execute for a managed service
+func (wsm *WindowsServiceManager) GetServiceImagePath(serviceID string) (string, error) {
+	wsm.mutex.RLock()
+	_, exists := wsm.services[serviceID]
+	wsm.mutex.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	return readServiceImagePath(serviceID)
+}
+
+// ServiceCommandLine is what Windows runs for a service and what the wrapper runs in turn
+type ServiceCommandLine struct {
+	ImagePath     string `json:"imagePath"`     // command line SCM starts, usually the wrapper
+	TargetCommand string `json:"targetCommand"` // executable and arguments run by the wrapper
+	WorkingDir    string `json:"workingDir"`
+}
+
+// GetServiceCommandLine returns the effective command lines of a service
+func (wsm *WindowsServiceManager) GetServiceCommandLine(serviceID string) (*ServiceCommandLine, error) {
+	wsm.mutex.RLock()
+	service, exists := wsm.services[serviceID]
+	var exePath, args, workingDir string
+	if exists {
+		exePath, args, workingDir = service.ExePath, service.Args, service.WorkingDir
+	}
+	wsm.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	imagePath, err := readServiceImagePath(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if workingDir == "" {
+		workingDir = filepath.Dir(exePath)
+	}
+
+	targetCommand := exePath
+	if strings.ContainsAny(exePath, " \t") {
+		targetCommand = fmt.Sprintf("\"%s\"", exePath)
+	}
+	if args != "" {
+		targetCommand += " " + args
+	}
+
+	return &ServiceCommandLine{
+		ImagePath:     imagePath,
+		TargetCommand: targetCommand,
+		WorkingDir:    workingDir,
+	}, nil
+}
+
+// resolvePath expands environment variables in a path and makes it absolute
+func resolvePath(path string) (string, error) {
+	expanded, err := registry.ExpandString(strings.Trim(path, "\""))
+	if err != nil {
+		return "", fmt.Errorf("failed to expand path %s: %v", path, err)
+	}
+	return filepath.Abs(expanded)
+}
+
+// expandServicePath expands environment variables in the executable path or working dir of a
+// service. Both are stored unexpanded so they stay portable and are expanded when used.
+func expandServicePath(path string) string {
+	path = strings.Trim(path, "\"")
+	expanded, err := registry.ExpandString(path)
+	if err != nil {
+		return path
+	}
+	return expanded
+}
+
+// serviceWorkingDir returns the expanded directory a service target runs in. A relative
+// workingDir is taken relative to the executable, an empty one means the executable's directory.
+func serviceWorkingDir(workingDir, exePath string) string {
+	exeDir := filepath.Dir(expandServicePath(exePath))
+	if workingDir == "" {
+		return exeDir
+	}
+
+	dir := expandServicePath(workingDir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(exeDir, dir)
+	}
+	return dir
 }
 
-// GetServiceLogPath retrieves the log file path from the registry.
-func (wsm *WindowsServiceManager) GetServiceLogPath(serviceID string) (string, uint32, error) {
-    keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Services\%s\Parameters`, serviceID)
-    k, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
-    if err != nil {
-        return keyPath, 0, err
-    }	
-    defer k.Close()
-    return k.GetStringValue("StdoutLog")
+// ensureWritableDir creates a directory if needed and proves it accepts new files
+func ensureWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create directory %s: %v", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".wsm-write-test-*")
+	if err != nil {
+		return fmt.Errorf("directory is not writable %s: %v", dir, err)
+	}
+	probeName := probe.Name()
+	_, err = probe.WriteString("ok")
+	probe.Close()
+	os.Remove(probeName)
+	if err != nil {
+		return fmt.Errorf("directory is not writable %s: %v", dir, err)
+	}
+
+	return nil
 }
 
 // SetContext sets the context for emitting events
@@ -71,7 +325,8 @@ func (wsm *WindowsServiceManager) SetContext(ctx context.Context) {
 
 // emitServiceStatusChanged emits a service status change event
 func (wsm *WindowsServiceManager) emitServiceStatusChanged(serviceID, status string, pid int) {
-	if wsm.ctx != nil {
+	wsm.recordWatchedStatus(serviceID, status, pid)
+	if wsm.ctx != nil && !wsm.quietStatusEvents {
 		runtime.EventsEmit(wsm.ctx, "service-status-changed", map[string]interface{}{
 			"serviceId": serviceID,
 			"status":    status,
@@ -91,25 +346,79 @@ func (wsm *WindowsServiceManager) emitServicesUpdated() {
 	}
 }
 
-// connectSCM connects to the Windows Service Control Manager
-func (wsm *WindowsServiceManager) connectSCM() (*mgr.Mgr, error) {
-	return mgr.Connect()
+// scmConnectAttempts is how often connectSCM tries before giving up
+const scmConnectAttempts = 3
+
+// scmConnectBackoff is the wait before the first retry; it doubles for each further one
+const scmConnectBackoff = 250 * time.Millisecond
+
+// connectSCM connects to the Windows Service Control Manager.
+// SCM can refuse connections for a moment right after boot or under heavy load, so failures
+// are retried with an increasing backoff; access denied is returned at once.
+func (wsm *WindowsServiceManager) connectSCM() (scmConnection, error) {
+	backoff := scmConnectBackoff
+	for attempt := 1; ; attempt++ {
+		scm, err := wsm.scm.Connect()
+		if err == nil {
+			return scm, nil
+		}
+		if errors.Is(err, windows.ERROR_ACCESS_DENIED) || attempt >= scmConnectAttempts {
+			return nil, err
+		}
+
+		fmt.Printf("Warning: failed to connect to service control manager (attempt %d of %d), retrying in %v: %v\n", attempt, scmConnectAttempts, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 }
 
 // withSCM is a helper to perform operations using SCM
-func (wsm *WindowsServiceManager) withSCM(operation func(*mgr.Mgr) error) error {
+func (wsm *WindowsServiceManager) withSCM(operation func(scmConnection) error) error {
 	scm, err := wsm.connectSCM()
 	if err != nil {
-		return fmt.Errorf("failed to connect to service control manager: %v", err)
+		return wrapAccessError("failed to connect to service control manager", err)
 	}
 	defer scm.Disconnect()
 
 	return operation(scm)
 }
 
-// waitForServiceState waits for a service to reach a specific state
-func (wsm *WindowsServiceManager) waitForServiceState(windowsService *mgr.Service, targetState svc.State, timeout time.Duration) error {
-	deadline := time.Now().Add(timeout)
+// defaultServiceWaitTimeout is how long start and stop wait for SCM when a service does not configure it
+const defaultServiceWaitTimeout = 30 * time.Second
+
+// stopWaitMargin is added to the wrapper's StopTimeoutSec so it can kill the target before SCM gives up
+const stopWaitMargin = 10 * time.Second
+
+// serviceWaitTimeouts returns how long to wait for a service to start and to stop
+func serviceWaitTimeouts(serviceID string) (time.Duration, time.Duration) {
+	start, stop := defaultServiceWaitTimeout, defaultServiceWaitTimeout
+
+	values := readServiceParameters(serviceID, "StartTimeoutSec", "StopTimeoutSec")
+	if sec, err := strconv.Atoi(values["StartTimeoutSec"]); err == nil && sec > 0 {
+		start = time.Duration(sec) * time.Second
+	}
+	if sec, err := strconv.Atoi(values["StopTimeoutSec"]); err == nil && sec > 0 {
+		if wait := time.Duration(sec)*time.Second + stopWaitMargin; wait > stop {
+			stop = wait
+		}
+	}
+	return start, stop
+}
+
+// operationContext returns the context that bounds long-running service operations
+func (wsm *WindowsServiceManager) operationContext() context.Context {
+	if wsm.ctx != nil {
+		return wsm.ctx
+	}
+	return context.Background()
+}
+
+// waitForServiceState waits for a service to reach a specific state, emitting service-status-progress on every poll.
+// While the service reports progress (a new CheckPoint), its WaitHint may extend the timeout.
+func (wsm *WindowsServiceManager) waitForServiceState(ctx context.Context, windowsService scmService, serviceID string, targetState svc.State, timeout time.Duration) error {
+	started := time.Now()
+	deadline := started.Add(timeout)
+	var lastCheckPoint uint32
 
 	for time.Now().Before(deadline) {
 		status, err := windowsService.Query()
@@ -117,18 +426,40 @@ func (wsm *WindowsServiceManager) waitForServiceState(windowsService *mgr.Servic
 			return fmt.Errorf("failed to query service status: %v", err)
 		}
 
+		if wsm.ctx != nil {
+			runtime.EventsEmit(wsm.ctx, "service-status-progress", map[string]interface{}{
+				"serviceId":  serviceID,
+				"state":      serviceStateName(status.State),
+				"target":     serviceStateName(targetState),
+				"elapsedMs":  time.Since(started).Milliseconds(),
+				"waitHintMs": status.WaitHint,
+				"checkPoint": status.CheckPoint,
+			})
+		}
+
 		if status.State == targetState {
 			return nil
 		}
 
+		if status.WaitHint > 0 && status.CheckPoint != lastCheckPoint {
+			lastCheckPoint = status.CheckPoint
+			if hinted := time.Now().Add(time.Duration(status.WaitHint) * time.Millisecond); hinted.After(deadline) {
+				deadline = hinted
+			}
+		}
+
 		if targetState == svc.Running && status.State == svc.Stopped {
 			return fmt.Errorf("service failed to start")
 		}
 
-		time.Sleep(500 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
 	}
 
-	return fmt.Errorf("timeout waiting for service state")
+	return fmt.Errorf("%w waiting for service state", ErrTimeout)
 }
 
 // setServiceRegistryValue sets a registry value for a service
@@ -144,25 +475,49 @@ func (wsm *WindowsServiceManager) setServiceRegistryValue(serviceName, subKey, v
 	if subKey != "" {
 		parentKey, err := registry.OpenKey(registry.LOCAL_MACHINE, fmt.Sprintf(`SYSTEM\CurrentControlSet\Services\%s`, serviceName), registry.SET_VALUE)
 		if err != nil {
-			return fmt.Errorf("failed to open service registry key: %v", err)
+			return wrapAccessError("failed to open service registry key", err)
 		}
 		defer parentKey.Close()
 
 		key, _, err = registry.CreateKey(parentKey, subKey, registry.SET_VALUE)
 		if err != nil {
-			return fmt.Errorf("failed to create registry subkey: %v", err)
+			return wrapAccessError("failed to create registry subkey", err)
 		}
 	} else {
 		key, err = registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.SET_VALUE)
 		if err != nil {
-			return fmt.Errorf("failed to open service registry key: %v", err)
+			return wrapAccessError("failed to open service registry key", err)
 		}
 	}
 	defer key.Close()
 
 	err = key.SetStringValue(valueName, value)
 	if err != nil {
-		return fmt.Errorf("failed to set registry value: %v", err)
+		return wrapAccessError("failed to set registry value", err)
+	}
+
+	return nil
+}
+
+// deleteServiceRegistryValue removes a value from a service's registry key, ignoring values that do not exist
+func (wsm *WindowsServiceManager) deleteServiceRegistryValue(serviceName, subKey, valueName string) error {
+	keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Services\%s`, serviceName)
+	if subKey != "" {
+		keyPath = fmt.Sprintf(`%s\%s`, keyPath, subKey)
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.SET_VALUE)
+	if err == registry.ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return wrapAccessError("failed to open service registry key", err)
+	}
+	defer key.Close()
+
+	err = key.DeleteValue(valueName)
+	if err != nil && err != registry.ErrNotExist {
+		return wrapAccessError("failed to delete registry value", err)
 	}
 
 	return nil
@@ -178,75 +533,276 @@ func (wsm *WindowsServiceManager) setServiceImagePathDirect(serviceName, imagePa
 	return wsm.setServiceRegistryValue(serviceName, "", "ImagePath", imagePath)
 }
 
-// createServiceWrapper sets up the built-in service wrapper (using current program + arguments mode)
-func (wsm *WindowsServiceManager) createServiceWrapper(serviceName, exePath, args, workingDir string) (string, error) {
+// legacyLogDirName is the folder under ProgramData earlier versions logged to
+const legacyLogDirName = "Windows Service Manager.exe"
+
+// programDataDir returns the machine-wide application data folder
+func programDataDir() string {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = `C:\ProgramData` // fallback
+	}
+	return programData
+}
+
+// defaultLogDir returns the log directory used unless SetLogDir chose another one.
+// It shares its folder name with the config dir.
+func defaultLogDir() string {
+	return filepath.Join(programDataDir(), configDirName, "logs")
+}
+
+// SetLogDir sets the directory new services log to; an empty dir means the default.
+// Existing services keep their log paths.
+func (wsm *WindowsServiceManager) SetLogDir(dir string) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	wsm.logDir = dir
+}
+
+// defaultLogPath returns the log file used when a service has no explicit log path,
+// in a folder of its own under the log directory; the caller holds the lock
+func (wsm *WindowsServiceManager) defaultLogPath(serviceName string) string {
+	dir := wsm.logDir
+	if dir == "" {
+		dir = defaultLogDir()
+	}
+	return filepath.Join(dir, serviceName, serviceName+".log")
+}
+
+// isDefaultLogPath reports whether logPath is where a service logs by default,
+// including the default of earlier versions that services created back then still use
+func (wsm *WindowsServiceManager) isDefaultLogPath(serviceName, logPath string) bool {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	if strings.EqualFold(logPath, wsm.defaultLogPath(serviceName)) {
+		return true
+	}
+	legacyPath := filepath.Join(programDataDir(), legacyLogDirName, "logs", serviceName, serviceName+".log")
+	return wsm.logDir == "" && strings.EqualFold(logPath, legacyPath)
+}
+
+// prepareLogDir creates the directory of a log file and verifies it is writable,
+// so a bad log location is reported at configuration time rather than at first start
+func prepareLogDir(logPath string) error {
+	if err := ensureWritableDir(filepath.Dir(logPath)); err != nil {
+		return fmt.Errorf("invalid log path %s: %w", logPath, err)
+	}
+	return nil
+}
+
+// wrapperImagePath returns the ImagePath that runs serviceName through the built-in wrapper
+func wrapperImagePath(serviceName string) (string, error) {
 	currentExe, err := os.Executable()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current executable path: %v", err)
 	}
+	return fmt.Sprintf(`"%s" --service-wrapper %s`, currentExe, serviceName), nil
+}
+
+// checkWrapperService refuses services whose ImagePath does not run this executable with --service-wrapper,
+// so deleting them cannot remove a real system service by mistake
+func checkWrapperService(serviceName string) error {
+	imagePath, err := readServiceImagePath(serviceName)
+	if err != nil {
+		return fmt.Errorf("cannot verify service %s before deleting it: %w", serviceName, err)
+	}
+
+	expected, err := wrapperImagePath(serviceName)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(imagePath), expected) {
+		return fmt.Errorf("service %s does not run through the Windows Service Manager wrapper (ImagePath: %s), delete it with force to remove it anyway", serviceName, imagePath)
+	}
+	return nil
+}
+
+// createServiceWrapper sets up the built-in service wrapper (using current program + arguments mode)
+func (wsm *WindowsServiceManager) createServiceWrapper(serviceName string, config ServiceConfig) (string, error) {
+	imagePath, err := wrapperImagePath(serviceName)
+	if err != nil {
+		return "", err
+	}
 
 	// Store the core config
-	err = wsm.storeServiceConfigInRegistry(serviceName, exePath, args, workingDir)
+	err = wsm.storeServiceConfigInRegistry(serviceName, config)
 	if err != nil {
-		return "", fmt.Errorf("failed to store service configuration: %v", err)
+		return "", fmt.Errorf("failed to store service configuration: %w", err)
 	}
 
-	// Define and store the log file paths in the registry as well
-	programData := os.Getenv("ProgramData")
-	if programData == "" {
-		programData = `C:\ProgramData` // fallback
+	return imagePath, nil
+}
+
+// registryValue is a string value under a service's Parameters key
+type registryValue struct {
+	Name  string
+	Value string
+}
+
+// stderrLogPath returns the file the wrapper sends the target's stderr to
+func stderrLogPath(config ServiceConfig) string {
+	if config.ErrorLogPath != "" {
+		return config.ErrorLogPath
 	}
-	logDir := filepath.Join(programData, "Windows Service Manager.exe", "logs")
-	os.MkdirAll(logDir, 0755)
-	Log := filepath.Join(logDir, serviceName + ".log")
+	return config.LogPath
+}
 
-	// Store log paths in registry
-	if err := wsm.setServiceRegistryValue(serviceName, "Parameters", "StdoutLog", Log); err != nil {
-		return "", fmt.Errorf("failed to set StdoutLog: %w", err)
+// serviceParameterValues returns the Parameters values that describe config,
+// and the names of optional values config leaves unset
+func serviceParameterValues(config ServiceConfig) ([]registryValue, []string, error) {
+	values := []registryValue{
+		{"ManagedBy", managedByMarker},
+		{"ExePath", config.ExePath},
 	}
-	if err := wsm.setServiceRegistryValue(serviceName, "Parameters", "StderrLog", Log); err != nil {
-		return "", fmt.Errorf("failed to set StderrLog: %w", err)
+	var cleared []string
+
+	if config.Args != "" {
+		values = append(values, registryValue{"Args", config.Args})
+	} else {
+		cleared = append(cleared, "Args")
 	}
 
-	return fmt.Sprintf(`"%s" --service-wrapper %s`, currentExe, serviceName), nil
+	if len(config.ArgsList) > 0 {
+		argsList, err := json.Marshal(config.ArgsList)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode ArgsList: %v", err)
+		}
+		values = append(values, registryValue{"ArgsList", string(argsList)})
+	} else {
+		cleared = append(cleared, "ArgsList")
+	}
+
+	if config.WorkingDir != "" {
+		values = append(values, registryValue{"WorkingDir", config.WorkingDir})
+	} else {
+		cleared = append(cleared, "WorkingDir")
+	}
+
+	values = append(values,
+		registryValue{"StdoutLog", config.LogPath},
+		registryValue{"StderrLog", stderrLogPath(config)},
+		registryValue{"LogMaxSizeMB", strconv.Itoa(config.LogMaxSizeMB)},
+		registryValue{"LogEncoding", strings.TrimSpace(config.LogEncoding)},
+		registryValue{"ListenPort", strconv.Itoa(config.ListenPort)},
+		registryValue{"StopTimeoutSec", strconv.Itoa(config.StopTimeoutSec)},
+		registryValue{"StartTimeoutSec", strconv.Itoa(config.StartTimeoutSec)},
+		registryValue{"RestartOnExit", strconv.FormatBool(config.RestartOnExit)},
+		registryValue{"MaxRestarts", strconv.Itoa(config.MaxRestarts)},
+	)
+
+	if config.LogMaxBackups != nil {
+		values = append(values, registryValue{"LogMaxBackups", formatLogMaxBackups(*config.LogMaxBackups)})
+	} else {
+		cleared = append(cleared, "LogMaxBackups")
+	}
+
+	if len(config.ExtraCommands) > 0 {
+		extraCommands, err := json.Marshal(config.ExtraCommands)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode ExtraCommands: %v", err)
+		}
+		values = append(values, registryValue{"ExtraCommands", string(extraCommands)})
+	} else {
+		cleared = append(cleared, "ExtraCommands")
+	}
+
+	if len(config.Env) > 0 {
+		env, err := json.Marshal(config.Env)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode Env: %v", err)
+		}
+		// Environment variables often carry credentials, so they are stored as DPAPI ciphertext
+		protected, err := protectSecret(string(env))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to protect Env: %v", err)
+		}
+		values = append(values, registryValue{"Env", protected})
+	} else {
+		cleared = append(cleared, "Env")
+	}
+
+	if config.HealthCheckType != "" {
+		values = append(values,
+			registryValue{"HealthCheckType", config.HealthCheckType},
+			registryValue{"HealthCheckTarget", config.HealthCheckTarget},
+			registryValue{"HealthCheckIntervalSec", strconv.Itoa(config.HealthCheckIntervalSec)},
+		)
+	} else {
+		cleared = append(cleared, "HealthCheckType", "HealthCheckTarget", "HealthCheckIntervalSec")
+	}
+
+	if config.IdleTimeout > 0 {
+		values = append(values,
+			registryValue{"IdleTimeout", config.IdleTimeout.String()},
+			registryValue{"IdleCriterion", config.IdleCriterion},
+		)
+	} else {
+		cleared = append(cleared, "IdleTimeout", "IdleCriterion")
+	}
+
+	return values, cleared, nil
 }
 
 // storeServiceConfigInRegistry stores service configuration in the registry
-func (wsm *WindowsServiceManager) storeServiceConfigInRegistry(serviceName, exePath, args, workingDir string) error {
-	if err := wsm.setServiceRegistryValue(serviceName, "Parameters", "ExePath", exePath); err != nil {
-		return fmt.Errorf("failed to set ExePath: %v", err)
+func (wsm *WindowsServiceManager) storeServiceConfigInRegistry(serviceName string, config ServiceConfig) error {
+	values, cleared, err := serviceParameterValues(config)
+	if err != nil {
+		return err
 	}
 
-	if args != "" {
-		if err := wsm.setServiceRegistryValue(serviceName, "Parameters", "Args", args); err != nil {
-			return fmt.Errorf("failed to set Args: %v", err)
+	for _, value := range values {
+		if err := wsm.setServiceRegistryValue(serviceName, "Parameters", value.Name, value.Value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", value.Name, err)
 		}
 	}
 
-	if workingDir != "" {
-		if err := wsm.setServiceRegistryValue(serviceName, "Parameters", "WorkingDir", workingDir); err != nil {
-			return fmt.Errorf("failed to set WorkingDir: %v", err)
+	for _, name := range cleared {
+		if err := wsm.deleteServiceRegistryValue(serviceName, "Parameters", name); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", name, err)
 		}
 	}
 
 	return nil
 }
 
-// GetServices returns all services managed by us
+// GetServices returns copies of all services managed by us, refreshed from SCM
 func (wsm *WindowsServiceManager) GetServices() ([]*Service, error) {
-	wsm.mutex.RLock()
-	defer wsm.mutex.RUnlock()
+	// The refresh updates the stored services, so this needs the write lock
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
 
 	var services []*Service
+	changed := false
 
-	err := wsm.withSCM(func(scm *mgr.Mgr) error {
+	err := wsm.withSCM(func(scm scmConnection) error {
 		services = make([]*Service, 0, len(wsm.services))
 		for _, service := range wsm.services {
+			previousStatus, previousPID, previousStartedAt := service.Status, service.PID, service.StartedAt
+			previousStartType := service.StartType
+
 			status, pid := wsm.getServiceRealTimeStatus(scm, service.ID)
 			service.Status = status
 			service.PID = pid
-			service.UpdatedAt = time.Now()
-			services = append(services, service)
+			if service.StartType == "" {
+				if startType, err := queryStartType(scm, service.ID); err == nil {
+					service.StartType = startType
+				}
+			}
+			loadLastExit(service)
+			wsm.refreshUptime(service)
+
+			if service.Status != previousStatus || service.PID != previousPID ||
+				!service.StartedAt.Equal(previousStartedAt) || service.StartType != previousStartType {
+				service.UpdatedAt = time.Now()
+				changed = true
+			}
+
+			// Callers read the result after the lock is released, so they get a snapshot
+			snapshot := *service
+			services = append(services, &snapshot)
 		}
 		return nil
 	})
@@ -255,63 +811,196 @@ func (wsm *WindowsServiceManager) GetServices() ([]*Service, error) {
 		return nil, err
 	}
 
-	go wsm.saveServices()
+	// The list is refreshed on every UI poll, so only write the data file when something changed
+	if changed {
+		wsm.saveServices()
+	}
 
 	return services, nil
 }
 
-// CreateService creates a system service using Windows SCM
-func (wsm *WindowsServiceManager) CreateService(config ServiceConfig) (*Service, error) {
+// reconcileRunningServices refreshes loaded services from live SCM state.
+// Services that came up at boot are reported with their real status, PID and start time
+// instead of the stale values persisted in the data file.
+func (wsm *WindowsServiceManager) reconcileRunningServices() {
 	wsm.mutex.Lock()
 	defer wsm.mutex.Unlock()
 
-	if _, err := os.Stat(config.ExePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("executable does not exist: %s", config.ExePath)
-	}
+	err := wsm.withSCM(func(scm scmConnection) error {
+		for _, service := range wsm.services {
+			wsm.statusCache.Remove(service.ID)
+			status, pid := wsm.getServiceRealTimeStatus(scm, service.ID)
 
-	serviceName := wsm.generateServiceName(config.Name)
+			if status == "running" && pid != 0 && (pid != service.PID || service.StartedAt.IsZero()) {
+				if startedAt, err := processCreationTime(pid); err == nil {
+					service.StartedAt = startedAt
+				}
+			} else if status != "running" {
+				service.StartedAt = time.Time{}
+			}
+
+			service.Status = status
+			service.PID = pid
+			service.UpdatedAt = time.Now()
+		}
+		return nil
+	})
 
-	if _, exists := wsm.services[serviceName]; exists {
-		return nil, fmt.Errorf("service name already exists: %s", serviceName)
+	if err != nil {
+		fmt.Printf("Warning: failed to reconcile service status: %v\n", err)
+		return
 	}
 
-	workingDir := config.WorkingDir
-	if workingDir == "" {
-		workingDir = filepath.Dir(config.ExePath)
+	wsm.saveServices()
+	wsm.emitServicesUpdated()
+}
+
+// ReconcileServices re-adds services created by this tool that exist in SCM but are missing
+// from the data file (for example after it was deleted or corrupted) and returns them
+func (wsm *WindowsServiceManager) ReconcileServices() ([]*Service, error) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	recovered := make([]*Service, 0)
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		names, err := scm.ListServices()
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+
+		for _, name := range names {
+			if _, exists := wsm.services[name]; exists || !wsm.isManagedByUs(name) {
+				continue
+			}
+
+			config, err := LoadServiceConfigFromRegistry(name)
+			if err != nil {
+				fmt.Printf("Warning: cannot recover service %s: %v\n", name, err)
+				continue
+			}
+
+			service := &Service{
+				ID:         name,
+				Name:       config.Name,
+				ExePath:    config.ExePath,
+				Args:       config.Args,
+				WorkingDir: config.WorkingDir,
+				LogPath:    config.LogPath,
+				Status:     "stopped",
+				CreatedAt:  time.Now(),
+				UpdatedAt:  time.Now(),
+			}
+
+			if windowsService, err := scm.OpenService(name); err == nil {
+				if scmConfig, err := windowsService.Config(); err == nil {
+					if service.Name == name && scmConfig.DisplayName != "" {
+						service.Name = scmConfig.DisplayName
+					}
+					service.LoadOrderGroup = scmConfig.LoadOrderGroup
+					service.Account = scmConfig.ServiceStartName
+					service.Dependencies = scmConfig.Dependencies
+					service.StartType = startTypeName(scmConfig)
+					service.AutoStart = service.StartType == "auto" || service.StartType == "delayed"
+				}
+				windowsService.Close()
+			}
+
+			service.Status, service.PID = wsm.getServiceRealTimeStatus(scm, name)
+			wsm.services[name] = service
+			recovered = append(recovered, service)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return recovered, err
+	}
+
+	if len(recovered) > 0 {
+		fmt.Printf("Recovered %d service(s) missing from the data file\n", len(recovered))
+		wsm.saveServices()
+		wsm.emitServicesUpdated()
+	}
+
+	return recovered, nil
+}
+
+// CreateService creates a system service using Windows SCM
+func (wsm *WindowsServiceManager) CreateService(config ServiceConfig) (*Service, error) {
+	service, err := wsm.createService(config)
+
+	serviceID := config.Name
+	if service != nil {
+		serviceID = service.ID
+	}
+	recordAudit(serviceID, "create", err)
+
+	return service, err
+}
+
+// createService does the work of CreateService
+func (wsm *WindowsServiceManager) createService(config ServiceConfig) (*Service, error) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	plan, resolved, err := wsm.planService(config)
+	if err != nil {
+		return nil, err
+	}
+	serviceName := plan.ServiceName
+	workingDir := plan.WorkingDir
+	logPath := plan.LogPath
+
+	if err := os.MkdirAll(serviceWorkingDir(workingDir, config.ExePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %v", err)
+	}
+	if err := prepareLogDir(logPath); err != nil {
+		return nil, err
+	}
+	if resolved.ErrorLogPath != "" {
+		if err := prepareLogDir(resolved.ErrorLogPath); err != nil {
+			return nil, err
+		}
 	}
 
 	var service *Service
 
-	err := wsm.withSCM(func(scm *mgr.Mgr) error {
+	err = wsm.withSCM(func(scm scmConnection) error {
 		serviceConfig := mgr.Config{
-			ServiceType:  windows.SERVICE_WIN32_OWN_PROCESS,
-			StartType:    mgr.StartAutomatic,
-			ErrorControl: mgr.ErrorNormal,
-			DisplayName:  config.Name,
-			Description:  fmt.Sprintf("Service created by Windows Service Manager: %s", config.Name),
+			ServiceType:      windows.SERVICE_WIN32_OWN_PROCESS,
+			StartType:        mgr.StartAutomatic,
+			ErrorControl:     mgr.ErrorNormal,
+			DisplayName:      config.Name,
+			Description:      serviceDescription(config),
+			LoadOrderGroup:   config.LoadOrderGroup,
+			ServiceStartName: config.Account,
+			Password:         config.Password,
+			Dependencies:     config.Dependencies,
 		}
 
-		binaryPath := config.ExePath
-		if config.Args != "" {
-			binaryPath = fmt.Sprintf("\"%s\" %s", config.ExePath, config.Args)
+		if err := checkDependencies(scm, serviceName, config.Dependencies); err != nil {
+			return err
 		}
 
+		binaryPath := windows.ComposeCommandLine(append([]string{config.ExePath}, resolved.ArgsList...))
+
 		windowsService, err := scm.CreateService(serviceName, binaryPath, serviceConfig)
 		if err != nil {
 			return fmt.Errorf("failed to create Windows service: %v", err)
 		}
 		defer windowsService.Close()
 
-		wrapperPath, err := wsm.createServiceWrapper(serviceName, config.ExePath, config.Args, workingDir)
+		wrapperPath, err := wsm.createServiceWrapper(serviceName, resolved)
 		if err != nil {
 			windowsService.Delete()
-			return fmt.Errorf("failed to create service wrapper: %v", err)
+			return fmt.Errorf("failed to create service wrapper: %w", err)
 		}
 
 		err = wsm.setServiceImagePathDirect(serviceName, wrapperPath)
 		if err != nil {
 			windowsService.Delete()
-			return fmt.Errorf("failed to set service path: %v", err)
+			return fmt.Errorf("failed to set service path: %w", err)
 		}
 
 		err = wsm.setServiceWorkingDirectory(serviceName, workingDir)
@@ -320,16 +1009,24 @@ func (wsm *WindowsServiceManager) CreateService(config ServiceConfig) (*Service,
 		}
 
 		service = &Service{
-			ID:         serviceName,
-			Name:       config.Name,
-			ExePath:    config.ExePath,
-			Args:       config.Args,
-			WorkingDir: workingDir,
-			Status:     "stopped",
-			PID:        0,
-			AutoStart:  false,
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
+			ID:             serviceName,
+			Name:           config.Name,
+			Description:    serviceDescription(config),
+			ExePath:        config.ExePath,
+			Args:           resolved.Args,
+			WorkingDir:     workingDir,
+			LogPath:        logPath,
+			ErrorLogPath:   resolved.ErrorLogPath,
+			LoadOrderGroup: config.LoadOrderGroup,
+			Account:        config.Account,
+			Dependencies:   config.Dependencies,
+			Tags:           normalizeTags(config.Tags),
+			Status:         "stopped",
+			PID:            0,
+			AutoStart:      serviceConfig.StartType == mgr.StartAutomatic,
+			StartType:      startTypeName(serviceConfig),
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
 		}
 
 		return nil
@@ -354,17 +1051,92 @@ func (wsm *WindowsServiceManager) CreateService(config ServiceConfig) (*Service,
 	return service, nil
 }
 
-// StartService starts a Windows service
-func (wsm *WindowsServiceManager) StartService(serviceID string) error {
+// editedServiceConfig returns current with the executable, arguments and working directory taken from edit.
+// Everything else is kept, so an edit does not reset settings the caller left out.
+func editedServiceConfig(current, edit ServiceConfig) ServiceConfig {
+	merged := current
+	merged.ExePath = edit.ExePath
+	merged.Args = edit.Args
+	merged.ArgsList = edit.ArgsList
+	merged.WorkingDir = edit.WorkingDir
+	if merged.WorkingDir == "" {
+		merged.WorkingDir = filepath.Dir(edit.ExePath)
+	}
+	return merged
+}
+
+// EditService changes the executable, arguments and working directory of a service, and its display name
+// and log paths when config sets them. All other settings are kept; the service must be stopped first.
+func (wsm *WindowsServiceManager) EditService(serviceID string, config ServiceConfig) (err error) {
+	defer func() { recordAudit(serviceID, "edit", err) }()
+
+	if err := normalizeArgs(&config); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(expandServicePath(config.ExePath)); os.IsNotExist(err) {
+		return fmt.Errorf("executable does not exist: %s", config.ExePath)
+	}
+
 	wsm.mutex.Lock()
 	defer wsm.mutex.Unlock()
 
 	service, exists := wsm.services[serviceID]
 	if !exists {
-		return fmt.Errorf("service does not exist: %s", serviceID)
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if service.Adopted {
+		return fmt.Errorf("service %s is not run by the built-in wrapper and cannot be edited", serviceID)
+	}
+
+	current, err := LoadServiceConfigFromRegistry(serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to load service configuration: %w", err)
+	}
+	merged := editedServiceConfig(*current, config)
+
+	// Resolve the directory the way the wrapper will, like createService does
+	if err := os.MkdirAll(serviceWorkingDir(merged.WorkingDir, merged.ExePath), 0755); err != nil {
+		return fmt.Errorf("failed to create working directory: %v", err)
+	}
+
+	logPath := service.LogPath
+	if config.LogPath != "" {
+		resolved, err := resolvePath(config.LogPath)
+		if err != nil {
+			return err
+		}
+		logPath = resolved
+	}
+	if logPath == "" {
+		logPath = wsm.defaultLogPath(serviceID)
+	}
+	if err := prepareLogDir(logPath); err != nil {
+		return err
+	}
+	merged.LogPath = logPath
+
+	errorLogPath := service.ErrorLogPath
+	if config.ErrorLogPath != "" {
+		resolved, err := resolvePath(config.ErrorLogPath)
+		if err != nil {
+			return err
+		}
+		errorLogPath = resolved
+	}
+	if errorLogPath != "" {
+		if err := prepareLogDir(errorLogPath); err != nil {
+			return err
+		}
+	}
+	merged.ErrorLogPath = errorLogPath
+
+	name := config.Name
+	if name == "" {
+		name = service.Name
 	}
 
-	return wsm.withSCM(func(scm *mgr.Mgr) error {
+	err = wsm.withSCM(func(scm scmConnection) error {
 		windowsService, err := scm.OpenService(serviceID)
 		if err != nil {
 			return fmt.Errorf("failed to open service: %v", err)
@@ -375,256 +1147,1126 @@ func (wsm *WindowsServiceManager) StartService(serviceID string) error {
 		if err != nil {
 			return fmt.Errorf("failed to query service status: %v", err)
 		}
+		if status.State != svc.Stopped {
+			return fmt.Errorf("service is running, stop it before editing")
+		}
 
-		if status.State == svc.Running {
-			return fmt.Errorf("service is already running")
+		if name != service.Name {
+			serviceConfig, err := windowsService.Config()
+			if err != nil {
+				return fmt.Errorf("failed to get service configuration: %v", err)
+			}
+			serviceConfig.DisplayName = name
+			if err := windowsService.UpdateConfig(serviceConfig); err != nil {
+				return fmt.Errorf("failed to update service configuration: %v", err)
+			}
 		}
 
-		err = windowsService.Start()
+		wrapperPath, err := wsm.createServiceWrapper(serviceID, merged)
 		if err != nil {
-			return fmt.Errorf("failed to start service: %v", err)
+			return fmt.Errorf("failed to update service wrapper: %w", err)
 		}
 
-		err = wsm.waitForServiceState(windowsService, svc.Running, 30*time.Second)
-		if err != nil {
-			service.Status = "error"
-			service.UpdatedAt = time.Now()
-			wsm.saveServices()
-			return err
+		if err := wsm.setServiceImagePathDirect(serviceID, wrapperPath); err != nil {
+			return fmt.Errorf("failed to set service path: %w", err)
 		}
 
-		status, _ = windowsService.Query()
-		service.Status = "running"
-		service.PID = int(status.ProcessId)
-		service.UpdatedAt = time.Now()
-		wsm.statusCache.Set(serviceID, "running", int(status.ProcessId))
-		wsm.saveServices()
-		
-		// Emit status change event
-		wsm.emitServiceStatusChanged(serviceID, "running", int(status.ProcessId))
+		if err := wsm.setServiceWorkingDirectory(serviceID, merged.WorkingDir); err != nil {
+			fmt.Printf("Warning: failed to set working directory: %v\n", err)
+		}
 
 		return nil
 	})
+
+	if err != nil {
+		return err
+	}
+
+	service.Name = name
+	service.ExePath = merged.ExePath
+	service.Args = merged.Args
+	service.WorkingDir = merged.WorkingDir
+	service.LogPath = merged.LogPath
+	service.ErrorLogPath = merged.ErrorLogPath
+	service.UpdatedAt = time.Now()
+	wsm.saveServices()
+
+	// Emit service list update event
+	wsm.emitServicesUpdated()
+
+	return nil
 }
 
-// StopService stops a Windows service
-func (wsm *WindowsServiceManager) StopService(serviceID string) error {
+// StartService starts a Windows service
+func (wsm *WindowsServiceManager) StartService(serviceID string) error {
 	wsm.mutex.Lock()
 	defer wsm.mutex.Unlock()
 
 	service, exists := wsm.services[serviceID]
 	if !exists {
-		return fmt.Errorf("service does not exist: %s", serviceID)
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
 	}
 
-	return wsm.withSCM(func(scm *mgr.Mgr) error {
-		windowsService, err := scm.OpenService(serviceID)
-		if err != nil {
-			return fmt.Errorf("failed to open service: %v", err)
-		}
-		defer windowsService.Close()
+	return wsm.withSCM(func(scm scmConnection) error {
+		return wsm.startServiceWithSCM(scm, serviceID, service)
+	})
+}
 
-		status, err := windowsService.Query()
-		if err != nil {
-			return fmt.Errorf("failed to query service status: %v", err)
-		}
+// startServiceWithSCM starts a service over an open SCM connection; the caller holds the lock
+func (wsm *WindowsServiceManager) startServiceWithSCM(scm scmConnection, serviceID string, service *Service) (err error) {
+	defer func() { recordAudit(serviceID, "start", err) }()
 
-		if status.State == svc.Stopped {
-			service.Status = "stopped"
-			service.PID = 0
-			service.UpdatedAt = time.Now()
-			wsm.saveServices()
-			return nil
-		}
+	windowsService, err := scm.OpenService(serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to open service: %v", err)
+	}
+	defer windowsService.Close()
 
-		_, err = windowsService.Control(svc.Stop)
-		if err != nil {
-			return fmt.Errorf("failed to send stop signal: %v", err)
-		}
+	status, err := windowsService.Query()
+	if err != nil {
+		return fmt.Errorf("failed to query service status: %v", err)
+	}
 
-		err = wsm.waitForServiceState(windowsService, svc.Stopped, 30*time.Second)
-		if err != nil {
+	if status.State == svc.Running {
+		return ErrServiceAlreadyRunning
+	}
+
+	if port, _ := strconv.Atoi(readServiceParameters(serviceID, "ListenPort")["ListenPort"]); port > 0 && status.State == svc.Stopped {
+		if err := checkPortAvailable(port); err != nil {
 			return err
 		}
+	}
 
-		service.Status = "stopped"
-		service.PID = 0
+	err = windowsService.Start()
+	if err == windows.ERROR_SERVICE_LOGON_FAILED {
+		return fmt.Errorf("failed to start service: account %s could not log on, check its password and that it has the \"Log on as a service\" right", service.Account)
+	}
+	if err == windows.ERROR_SERVICE_DEPENDENCY_FAIL || err == windows.ERROR_SERVICE_DEPENDENCY_DELETED {
+		return fmt.Errorf("failed to start service: a dependency (%s) could not be started: %v", strings.Join(service.Dependencies, ", "), err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start service: %v", err)
+	}
+
+	startTimeout, _ := serviceWaitTimeouts(serviceID)
+	err = wsm.waitForServiceState(wsm.operationContext(), windowsService, serviceID, svc.Running, startTimeout)
+	if err != nil {
+		// The service may still come up, so its cached status cannot be trusted
+		wsm.statusCache.Invalidate(serviceID)
+		service.Status = "error"
+		// A hung target leaves the service starting, which the UI offers to force stop
+		if current, queryErr := windowsService.Query(); queryErr == nil && current.State == svc.StartPending {
+			service.Status = "starting"
+		}
 		service.UpdatedAt = time.Now()
-		wsm.statusCache.Set(serviceID, "stopped", 0)
 		wsm.saveServices()
-		
-		// Emit status change event
-		wsm.emitServiceStatusChanged(serviceID, "stopped", 0)
+		return err
+	}
 
-		return nil
-	})
+	status, _ = windowsService.Query()
+	service.Status = "running"
+	service.PID = int(status.ProcessId)
+	service.StartedAt = time.Now()
+	service.UpdatedAt = time.Now()
+	wsm.statusCache.Set(serviceID, "running", int(status.ProcessId))
+	wsm.saveServices()
+
+	// Emit status change event
+	wsm.emitServiceStatusChanged(serviceID, "running", int(status.ProcessId))
+
+	return nil
 }
 
-// DeleteService deletes a Windows service
-func (wsm *WindowsServiceManager) DeleteService(serviceID string) error {
+// StopService stops a Windows service.
+// Critical services are only stopped after the action is confirmed.
+func (wsm *WindowsServiceManager) StopService(serviceID string) error {
+	if err := wsm.requireConfirmation(serviceID, "stop"); err != nil {
+		return err
+	}
+	return wsm.stopService(serviceID)
+}
+
+// stopService stops a Windows service without any confirmation checks
+func (wsm *WindowsServiceManager) stopService(serviceID string) error {
 	wsm.mutex.Lock()
 	defer wsm.mutex.Unlock()
 
-	_, exists := wsm.services[serviceID]
+	service, exists := wsm.services[serviceID]
 	if !exists {
-		return fmt.Errorf("service does not exist: %s", serviceID)
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
 	}
 
-	return wsm.withSCM(func(scm *mgr.Mgr) error {
-		windowsService, err := scm.OpenService(serviceID)
-		if err != nil {
-			return fmt.Errorf("failed to open service: %v", err)
-		}
-		defer windowsService.Close()
-
-		status, err := windowsService.Query()
-		if err == nil && status.State != svc.Stopped {
-			windowsService.Control(svc.Stop)
-
-			wsm.waitForServiceState(windowsService, svc.Stopped, 30*time.Second)
-		}
-
-		err = windowsService.Delete()
-		if err != nil {
-			return fmt.Errorf("failed to delete service: %v", err)
-		}
-
-		delete(wsm.services, serviceID)
-		wsm.statusCache.Remove(serviceID)
-		wsm.saveServices()
-		
-		// Emit service list update event
-		wsm.emitServicesUpdated()
-
-		return nil
+	return wsm.withSCM(func(scm scmConnection) error {
+		return wsm.stopServiceWithSCM(scm, serviceID, service)
 	})
 }
 
-// getServiceRealTimeStatus gets real-time service status (using cache optimization)
-func (wsm *WindowsServiceManager) getServiceRealTimeStatus(scm *mgr.Mgr, serviceName string) (string, int) {
-	if cachedStatus, found := wsm.statusCache.Get(serviceName); found {
-		return cachedStatus.Status, cachedStatus.PID
-	}
+// stopServiceWithSCM stops a service over an open SCM connection; the caller holds the lock
+func (wsm *WindowsServiceManager) stopServiceWithSCM(scm scmConnection, serviceID string, service *Service) (err error) {
+	defer func() { recordAudit(serviceID, "stop", err) }()
 
-	windowsService, err := scm.OpenService(serviceName)
+	windowsService, err := scm.OpenService(serviceID)
 	if err != nil {
-		wsm.statusCache.Set(serviceName, "error", 0)
-		return "error", 0
+		return fmt.Errorf("failed to open service: %v", err)
 	}
 	defer windowsService.Close()
 
 	status, err := windowsService.Query()
 	if err != nil {
-		wsm.statusCache.Set(serviceName, "error", 0)
-		return "error", 0
+		return fmt.Errorf("failed to query service status: %v", err)
 	}
 
-	var statusStr string
-	var pid int
+	if status.State == svc.Stopped {
+		service.Status = "stopped"
+		service.PID = 0
+		service.StartedAt = time.Time{}
+		service.UpdatedAt = time.Now()
+		wsm.saveServices()
+		return nil
+	}
 
-	switch status.State {
-	case svc.Running:
-		statusStr = "running"
-		pid = int(status.ProcessId)
-	case svc.Stopped:
-		statusStr = "stopped"
-		pid = 0
-	case svc.StartPending:
-		statusStr = "starting"
-		pid = 0
-	case svc.StopPending:
-		statusStr = "stopping"
-		pid = int(status.ProcessId)
-	default:
-		statusStr = "error"
-		pid = 0
+	_, err = windowsService.Control(svc.Stop)
+	if err != nil {
+		wsm.statusCache.Invalidate(serviceID)
+		return fmt.Errorf("failed to send stop signal: %v", err)
 	}
 
-	// Update cache
-	wsm.statusCache.Set(serviceName, statusStr, pid)
-	return statusStr, pid
-}
+	_, stopTimeout := serviceWaitTimeouts(serviceID)
+	err = wsm.waitForServiceState(wsm.operationContext(), windowsService, serviceID, svc.Stopped, stopTimeout)
+	if err != nil {
+		wsm.statusCache.Invalidate(serviceID)
+		return err
+	}
 
-// generateServiceName generates a unique service name
-func (wsm *WindowsServiceManager) generateServiceName(displayName string) string {
-	cleanName := strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
-			return r
-		}
-		return '_'
-	}, displayName)
+	service.Status = "stopped"
+	service.PID = 0
+	service.StartedAt = time.Time{}
+	service.UpdatedAt = time.Now()
+	wsm.statusCache.Set(serviceID, "stopped", 0)
+	wsm.saveServices()
 
-	return fmt.Sprintf("WSM_%s_%d", cleanName, time.Now().Unix())
-}
+	// Emit status change event
+	wsm.emitServiceStatusChanged(serviceID, "stopped", 0)
 
-// saveServices saves service data to file
-func (wsm *WindowsServiceManager) saveServices() {
-	data, err := json.MarshalIndent(wsm.services, "", "  ")
-	if err != nil {
-		return
-	}
-	os.WriteFile(wsm.dataFile, data, 0644)
+	return nil
 }
 
-// loadServices loads service data from file
-func (wsm *WindowsServiceManager) loadServices() {
-	if _, err := os.Stat(wsm.dataFile); os.IsNotExist(err) {
-		return
-	}
-
-	data, err := os.ReadFile(wsm.dataFile)
-	if err != nil {
-		return
+// ForceStopService stops a service stuck starting or stopping by terminating its process.
+// The service process is the wrapper, so this is the remedy when a target hangs.
+func (wsm *WindowsServiceManager) ForceStopService(serviceID string) error {
+	if err := wsm.requireConfirmation(serviceID, "force-stop"); err != nil {
+		return err
 	}
-
-	json.Unmarshal(data, &wsm.services)
+	return wsm.forceStopService(serviceID)
 }
 
-// SetServiceAutoStart sets whether a service starts automatically at boot
-func (wsm *WindowsServiceManager) SetServiceAutoStart(serviceID string, enabled bool) error {
+// forceStopService implements ForceStopService without the critical confirmation
+func (wsm *WindowsServiceManager) forceStopService(serviceID string) (err error) {
+	defer func() { recordAudit(serviceID, "force-stop", err) }()
+
 	wsm.mutex.Lock()
 	defer wsm.mutex.Unlock()
 
 	service, exists := wsm.services[serviceID]
 	if !exists {
-		return fmt.Errorf("service does not exist: %s", serviceID)
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
 	}
 
-	return wsm.withSCM(func(scm *mgr.Mgr) error {
+	return wsm.withSCM(func(scm scmConnection) error {
 		windowsService, err := scm.OpenService(serviceID)
 		if err != nil {
 			return fmt.Errorf("failed to open service: %v", err)
 		}
 		defer windowsService.Close()
 
-		// Get current service configuration
-		config, err := windowsService.Config()
+		status, err := windowsService.Query()
 		if err != nil {
-			return fmt.Errorf("failed to get service configuration: %v", err)
+			return fmt.Errorf("failed to query service status: %v", err)
 		}
 
-		// Modify start type
-		if enabled {
-			config.StartType = mgr.StartAutomatic
-		} else {
-			config.StartType = mgr.StartManual
+		switch status.State {
+		case svc.Stopped:
+			return ErrServiceAlreadyStopped
+		case svc.StartPending, svc.StopPending:
+		default:
+			return fmt.Errorf("service is %s, not stuck starting or stopping, stop it normally", serviceStateName(status.State))
 		}
 
-		// Update service configuration
-		err = windowsService.UpdateConfig(config)
-		if err != nil {
-			return fmt.Errorf("failed to update service configuration: %v", err)
+		// A service still starting usually rejects the stop control; the process is terminated either way
+		if _, err := windowsService.Control(svc.Stop); err != nil {
+			fmt.Printf("Warning: failed to send stop signal to %s: %v\n", serviceID, err)
 		}
 
-		// Update in-memory service info
-		service.AutoStart = enabled
+		if err := wsm.terminateServiceProcess(windowsService); err != nil {
+			wsm.statusCache.Invalidate(serviceID)
+			return fmt.Errorf("failed to force stop service: %w", err)
+		}
+
+		service.Status = "stopped"
+		service.PID = 0
+		service.StartedAt = time.Time{}
 		service.UpdatedAt = time.Now()
+		wsm.statusCache.Set(serviceID, "stopped", 0)
 		wsm.saveServices()
 
+		// Emit status change event
+		wsm.emitServiceStatusChanged(serviceID, "stopped", 0)
+
 		return nil
 	})
 }
 
+// RestartService stops a Windows service and starts it again while holding the lock once,
+// so no other operation can observe or change the service in between.
+// A stopped service is simply started. Critical services are only restarted after the action is confirmed.
+func (wsm *WindowsServiceManager) RestartService(serviceID string) error {
+	if err := wsm.requireConfirmation(serviceID, "restart"); err != nil {
+		return err
+	}
+	return wsm.restartService(serviceID)
+}
+
+// ReloadService restarts a service so it picks up a rebuilt executable and any changes made to its
+// configuration with EditService. It fails without stopping the service if the executable is gone.
+func (wsm *WindowsServiceManager) ReloadService(serviceID string) error {
+	if err := wsm.requireConfirmation(serviceID, "reload"); err != nil {
+		return err
+	}
+	return wsm.reloadService(serviceID)
+}
+
+// reloadService reloads a service without any confirmation checks
+func (wsm *WindowsServiceManager) reloadService(serviceID string) (err error) {
+	defer func() { recordAudit(serviceID, "reload", err) }()
+
+	wsm.mutex.RLock()
+	service, exists := wsm.services[serviceID]
+	adopted := exists && service.Adopted
+	wsm.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if adopted {
+		return fmt.Errorf("service %s is not run by the built-in wrapper and cannot be reloaded, restart it instead", serviceID)
+	}
+
+	// The wrapper reads its configuration from the registry when it starts, so check what it will run
+	config, err := LoadServiceConfigFromRegistry(serviceID)
+	if err != nil {
+		return err
+	}
+	if err := validateExecutable(config.ExePath); err != nil {
+		return err
+	}
+
+	if wsm.ctx != nil {
+		runtime.EventsEmit(wsm.ctx, "service-status-progress", map[string]interface{}{
+			"serviceId": serviceID,
+			"state":     "reloading",
+			"target":    "running",
+		})
+	}
+
+	if err := wsm.restartService(serviceID); err != nil {
+		return err
+	}
+
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	if service, exists := wsm.services[serviceID]; exists {
+		service.ExePath = config.ExePath
+		service.Args = config.Args
+		service.WorkingDir = config.WorkingDir
+		if config.LogPath != "" {
+			service.LogPath = config.LogPath
+		}
+		service.ErrorLogPath = config.ErrorLogPath
+		service.UpdatedAt = time.Now()
+		wsm.saveServices()
+	}
+	return nil
+}
+
+// restartService restarts a Windows service without any confirmation checks
+func (wsm *WindowsServiceManager) restartService(serviceID string) (err error) {
+	defer func() { recordAudit(serviceID, "restart", err) }()
+
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	startTimeout, stopTimeout := serviceWaitTimeouts(serviceID)
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		status, err := windowsService.Query()
+		if err != nil {
+			return fmt.Errorf("failed to query service status: %v", err)
+		}
+
+		if status.State != svc.Stopped {
+			if status.State != svc.StopPending {
+				_, err = windowsService.Control(svc.Stop)
+				if err != nil {
+					wsm.statusCache.Invalidate(serviceID)
+					return fmt.Errorf("failed to send stop signal: %v", err)
+				}
+			}
+
+			err = wsm.waitForServiceState(wsm.operationContext(), windowsService, serviceID, svc.Stopped, stopTimeout)
+			if err != nil {
+				wsm.statusCache.Invalidate(serviceID)
+				return err
+			}
+		}
+
+		err = windowsService.Start()
+		if err != nil {
+			service.Status = "stopped"
+			service.PID = 0
+			service.UpdatedAt = time.Now()
+			wsm.statusCache.Set(serviceID, "stopped", 0)
+			wsm.saveServices()
+			wsm.emitServiceStatusChanged(serviceID, "stopped", 0)
+			return fmt.Errorf("failed to start service: %v", err)
+		}
+
+		err = wsm.waitForServiceState(wsm.operationContext(), windowsService, serviceID, svc.Running, startTimeout)
+		if err != nil {
+			service.Status = "error"
+			service.PID = 0
+			service.UpdatedAt = time.Now()
+			wsm.statusCache.Invalidate(serviceID)
+			wsm.saveServices()
+			wsm.emitServiceStatusChanged(serviceID, "error", 0)
+			return err
+		}
+
+		status, _ = windowsService.Query()
+		service.Status = "running"
+		service.PID = int(status.ProcessId)
+		service.StartedAt = time.Now()
+		service.UpdatedAt = time.Now()
+		wsm.statusCache.Set(serviceID, "running", int(status.ProcessId))
+		wsm.saveServices()
+
+		// Emit a single status change event for the whole restart
+		wsm.emitServiceStatusChanged(serviceID, "running", int(status.ProcessId))
+
+		return nil
+	})
+}
+
+// PauseService pauses a running Windows service that accepts pause and continue
+func (wsm *WindowsServiceManager) PauseService(serviceID string) error {
+	return wsm.pauseOrContinueService(serviceID, svc.Pause, svc.Paused, "paused")
+}
+
+// ContinueService resumes a paused Windows service
+func (wsm *WindowsServiceManager) ContinueService(serviceID string) error {
+	return wsm.pauseOrContinueService(serviceID, svc.Continue, svc.Running, "running")
+}
+
+// pauseOrContinueService sends a pause or continue control and waits for the resulting state
+func (wsm *WindowsServiceManager) pauseOrContinueService(serviceID string, cmd svc.Cmd, targetState svc.State, statusStr string) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		return wsm.pauseOrContinueWithSCM(scm, serviceID, service, cmd, targetState, statusStr)
+	})
+}
+
+// pauseOrContinueWithSCM pauses or continues a service over an open SCM connection; the caller holds the lock
+func (wsm *WindowsServiceManager) pauseOrContinueWithSCM(scm scmConnection, serviceID string, service *Service, cmd svc.Cmd, targetState svc.State, statusStr string) error {
+	windowsService, err := scm.OpenService(serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to open service: %v", err)
+	}
+	defer windowsService.Close()
+
+	status, err := windowsService.Query()
+	if err != nil {
+		return fmt.Errorf("failed to query service status: %v", err)
+	}
+
+	if status.Accepts&svc.AcceptPauseAndContinue == 0 {
+		return fmt.Errorf("service does not support pause and continue")
+	}
+
+	if status.State == svc.Stopped {
+		return ErrServiceAlreadyStopped
+	}
+
+	if status.State != targetState {
+		_, err = windowsService.Control(cmd)
+		if err != nil {
+			wsm.statusCache.Invalidate(serviceID)
+			return fmt.Errorf("failed to send %s signal: %v", statusStr, err)
+		}
+
+		err = wsm.waitForServiceState(wsm.operationContext(), windowsService, serviceID, targetState, defaultServiceWaitTimeout)
+		if err != nil {
+			wsm.statusCache.Invalidate(serviceID)
+			return err
+		}
+	}
+
+	status, _ = windowsService.Query()
+	service.Status = statusStr
+	service.PID = int(status.ProcessId)
+	service.UpdatedAt = time.Now()
+	wsm.statusCache.Set(serviceID, statusStr, int(status.ProcessId))
+	wsm.saveServices()
+
+	// Emit status change event
+	wsm.emitServiceStatusChanged(serviceID, statusStr, int(status.ProcessId))
+
+	return nil
+}
+
+// DeleteService deletes a Windows service.
+// If the service does not stop in time it is left in place rather than soft-deleted.
+// Services that do not run through the built-in wrapper are only deleted when force is set.
+// A service that is not stopped needs the token from RequestServiceDeletion.
+func (wsm *WindowsServiceManager) DeleteService(serviceID string, force bool, token string) error {
+	action := "delete"
+	if force {
+		action = "delete-non-wrapper"
+	}
+	if err := wsm.requireConfirmation(serviceID, action); err != nil {
+		return err
+	}
+	if err := wsm.checkDeletionToken(serviceID, token); err != nil {
+		return err
+	}
+	return wsm.deleteService(serviceID, false, force)
+}
+
+// DeletionRequest describes what deleting a service removes, with the token that authorizes it
+type DeletionRequest struct {
+	Token     string `json:"token"`
+	ServiceID string `json:"serviceId"`
+	Name      string `json:"name"`
+	ExePath   string `json:"exePath"`
+	LogPath   string `json:"logPath"`
+	Status    string `json:"status"`
+	PID       int    `json:"pid"`
+}
+
+// RequestServiceDeletion returns the details of a service and a short-lived token
+// that DeleteService accepts once for it
+func (wsm *WindowsServiceManager) RequestServiceDeletion(serviceID string) (*DeletionRequest, error) {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	request := &DeletionRequest{
+		ServiceID: serviceID,
+		Name:      service.Name,
+		ExePath:   service.ExePath,
+		LogPath:   service.LogPath,
+	}
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		request.Status, request.PID = queryServiceStatus(scm, serviceID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	request.Token = wsm.confirmations.issue(serviceID, "delete-request")
+	return request, nil
+}
+
+// checkDeletionToken lets a stopped service be deleted without a token; any other service
+// needs a valid token from RequestServiceDeletion
+func (wsm *WindowsServiceManager) checkDeletionToken(serviceID, token string) error {
+	if token != "" {
+		pending, ok := wsm.confirmations.consume(token)
+		if !ok || pending.serviceID != serviceID || pending.action != "delete-request" {
+			return fmt.Errorf("deletion token is invalid or expired, request the deletion again")
+		}
+		return nil
+	}
+
+	var status string
+	err := wsm.withSCM(func(scm scmConnection) error {
+		status, _ = queryServiceStatus(scm, serviceID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !deletableWithoutToken(status) {
+		return fmt.Errorf("service %s is %s, confirm the deletion with a token from RequestServiceDeletion", serviceID, status)
+	}
+	return nil
+}
+
+// deletableWithoutToken reports whether a service in status may be deleted without confirmation
+func deletableWithoutToken(status string) bool {
+	return status == "stopped" || status == "error"
+}
+
+// ForceDeleteService deletes a Windows service, terminating its process if it will not stop
+func (wsm *WindowsServiceManager) ForceDeleteService(serviceID string) error {
+	if err := wsm.requireConfirmation(serviceID, "force-delete"); err != nil {
+		return err
+	}
+	return wsm.deleteService(serviceID, true, false)
+}
+
+// deleteService stops and deletes a service, optionally terminating a process that ignores the stop request.
+// allowNonWrapper permits deleting a service whose ImagePath is not the built-in wrapper.
+func (wsm *WindowsServiceManager) deleteService(serviceID string, terminate, allowNonWrapper bool) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	_, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		return wsm.deleteServiceWithSCM(scm, serviceID, terminate, allowNonWrapper)
+	})
+}
+
+// deleteServiceWithSCM stops and deletes a service over an open SCM connection; the caller holds the lock
+func (wsm *WindowsServiceManager) deleteServiceWithSCM(scm scmConnection, serviceID string, terminate, allowNonWrapper bool) (err error) {
+	defer func() { recordAudit(serviceID, "delete", err) }()
+
+	if !allowNonWrapper {
+		if service, exists := wsm.services[serviceID]; exists && service.Adopted {
+			return fmt.Errorf("service %s was not created by Windows Service Manager and will not be deleted, release it instead or delete it with force", serviceID)
+		}
+		if err := checkWrapperService(serviceID); err != nil {
+			return err
+		}
+	}
+
+	windowsService, err := scm.OpenService(serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to open service: %v", err)
+	}
+	defer windowsService.Close()
+
+	status, err := windowsService.Query()
+	if err == nil && status.State != svc.Stopped {
+		windowsService.Control(svc.Stop)
+
+		_, stopTimeout := serviceWaitTimeouts(serviceID)
+		err = wsm.waitForServiceState(wsm.operationContext(), windowsService, serviceID, svc.Stopped, stopTimeout)
+		if err != nil {
+			if !terminate {
+				return fmt.Errorf("service did not stop, use force delete to terminate it: %v", err)
+			}
+			if err := wsm.terminateServiceProcess(windowsService); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = windowsService.Delete()
+	if err != nil {
+		return fmt.Errorf("failed to delete service: %v", err)
+	}
+
+	removeEventSource(serviceID)
+
+	delete(wsm.services, serviceID)
+	wsm.statusCache.Remove(serviceID)
+	wsm.saveServices()
+
+	// Emit service list update event
+	wsm.emitServicesUpdated()
+
+	// SCM only removes a service once its process has exited
+	if status, err := windowsService.Query(); err == nil && status.State != svc.Stopped {
+		wsm.emitServiceStatusChanged(serviceID, "marked-for-deletion", int(status.ProcessId))
+		return fmt.Errorf("service is marked for deletion but still running (PID %d), it will be removed when the process exits", status.ProcessId)
+	}
+
+	return nil
+}
+
+// terminateServiceProcess kills the process behind a service that ignored a stop request
+func (wsm *WindowsServiceManager) terminateServiceProcess(windowsService scmService) error {
+	status, err := windowsService.Query()
+	if err != nil {
+		return fmt.Errorf("failed to query service status: %v", err)
+	}
+	if status.State == svc.Stopped {
+		return nil
+	}
+	if status.ProcessId == 0 {
+		return fmt.Errorf("service has no process to terminate")
+	}
+
+	if err := terminateProcess(int(status.ProcessId)); err != nil {
+		return err
+	}
+
+	return wsm.waitForServiceState(wsm.operationContext(), windowsService, windowsService.Name(), svc.Stopped, 10*time.Second)
+}
+
+// StartServices starts several services over a single SCM connection.
+// It returns serviceID -> error message, with an empty message for each service that started.
+func (wsm *WindowsServiceManager) StartServices(serviceIDs []string) map[string]string {
+	return wsm.batchServiceOperation(serviceIDs, "", func(scm scmConnection, serviceID string, service *Service) error {
+		return wsm.startServiceWithSCM(scm, serviceID, service)
+	})
+}
+
+// StopServices stops several services over a single SCM connection.
+// Critical services are not stopped and report an error asking for an individual, confirmed stop.
+func (wsm *WindowsServiceManager) StopServices(serviceIDs []string) map[string]string {
+	return wsm.batchServiceOperation(serviceIDs, "stop", func(scm scmConnection, serviceID string, service *Service) error {
+		return wsm.stopServiceWithSCM(scm, serviceID, service)
+	})
+}
+
+// DeleteServices deletes several services over a single SCM connection.
+// Critical services and services that are not stopped are not deleted; like DeleteService without
+// a token, they report an error asking for an individual, confirmed delete.
+func (wsm *WindowsServiceManager) DeleteServices(serviceIDs []string) map[string]string {
+	return wsm.batchServiceOperation(serviceIDs, "delete", func(scm scmConnection, serviceID string, service *Service) error {
+		if status, _ := queryServiceStatus(scm, serviceID); !deletableWithoutToken(status) {
+			return fmt.Errorf("service %s is %s and requires confirmation, delete it individually with a token from RequestServiceDeletion", serviceID, status)
+		}
+		return wsm.deleteServiceWithSCM(scm, serviceID, false, false)
+	})
+}
+
+// batchServiceOperation runs operation for every service while holding the lock and one SCM connection.
+// When guardedAction is set, critical services are skipped.
+func (wsm *WindowsServiceManager) batchServiceOperation(serviceIDs []string, guardedAction string, operation func(scmConnection, string, *Service) error) map[string]string {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	return wsm.runServiceOperation(serviceIDs, guardedAction, operation)
+}
+
+// runServiceOperation does the work of batchServiceOperation; the caller holds the lock
+func (wsm *WindowsServiceManager) runServiceOperation(serviceIDs []string, guardedAction string, operation func(scmConnection, string, *Service) error) map[string]string {
+	results := make(map[string]string, len(serviceIDs))
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		for _, serviceID := range serviceIDs {
+			service, exists := wsm.services[serviceID]
+			if !exists {
+				results[serviceID] = fmt.Sprintf("%v: %s", ErrServiceNotFound, serviceID)
+				continue
+			}
+
+			if guardedAction != "" && service.Critical {
+				results[serviceID] = fmt.Sprintf("service %s is critical, the %s action must be performed individually", serviceID, guardedAction)
+				continue
+			}
+
+			if err := operation(scm, serviceID, service); err != nil {
+				results[serviceID] = err.Error()
+				continue
+			}
+			results[serviceID] = ""
+		}
+		return nil
+	})
+
+	if err != nil {
+		for _, serviceID := range serviceIDs {
+			results[serviceID] = err.Error()
+		}
+	}
+
+	return results
+}
+
+// serviceStateName maps an SCM service state to the status string shown in the UI
+func serviceStateName(state svc.State) string {
+	switch state {
+	case svc.Running:
+		return "running"
+	case svc.Stopped:
+		return "stopped"
+	case svc.StartPending:
+		return "starting"
+	case svc.StopPending:
+		return "stopping"
+	case svc.Paused, svc.PausePending, svc.ContinuePending:
+		return "paused"
+	default:
+		return "error"
+	}
+}
+
+// getServiceRealTimeStatus gets real-time service status (using cache optimization).
+// Concurrent callers missing the cache for the same service share a single SCM query.
+func (wsm *WindowsServiceManager) getServiceRealTimeStatus(scm scmConnection, serviceName string) (string, int) {
+	return wsm.statusCache.Load(serviceName, func() (string, int) {
+		return queryServiceStatus(scm, serviceName)
+	})
+}
+
+// queryServiceStatus asks SCM for the current status and PID of a service, bypassing the cache
+func queryServiceStatus(scm scmConnection, serviceName string) (string, int) {
+	windowsService, err := scm.OpenService(serviceName)
+	if err != nil {
+		return "error", 0
+	}
+	defer windowsService.Close()
+
+	status, err := windowsService.Query()
+	if err != nil {
+		return "error", 0
+	}
+
+	pid := 0
+	switch status.State {
+	case svc.Running, svc.StopPending, svc.Paused, svc.PausePending, svc.ContinuePending:
+		pid = int(status.ProcessId)
+	}
+
+	return serviceStateName(status.State), pid
+}
+
+// generateServiceName generates a service name that is neither managed nor registered with SCM.
+// The nanosecond timestamp and random suffix keep names apart even for services with the same
+// display name created in quick succession.
+func (wsm *WindowsServiceManager) generateServiceName(displayName string) string {
+	cleanName := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, displayName)
+
+	for {
+		suffix := make([]byte, 2)
+		rand.Read(suffix)
+
+		name := fmt.Sprintf("%s%s_%s%s", wsm.namePrefix, cleanName,
+			strconv.FormatInt(time.Now().UnixNano(), 36), hex.EncodeToString(suffix))
+		if !wsm.serviceNameTaken(name) {
+			return name
+		}
+	}
+}
+
+// maxServiceNameLength is the longest service name SCM accepts
+const maxServiceNameLength = 256
+
+// validateServiceName checks a caller-supplied service name against the SCM naming rules
+func validateServiceName(name string) error {
+	if len(name) > maxServiceNameLength {
+		return fmt.Errorf("service name must be at most %d characters", maxServiceNameLength)
+	}
+	if strings.ContainsAny(name, " \t/\\") {
+		return fmt.Errorf("service name must not contain spaces or slashes")
+	}
+	return nil
+}
+
+// serviceNameTaken reports whether a service with this name is managed or already registered with SCM
+func (wsm *WindowsServiceManager) serviceNameTaken(name string) bool {
+	for id := range wsm.services {
+		if strings.EqualFold(id, name) {
+			return true
+		}
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+name, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	key.Close()
+	return true
+}
+
+// SetNamePrefix sets the prefix used by generateServiceName for new services
+func (wsm *WindowsServiceManager) SetNamePrefix(prefix string) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	wsm.namePrefix = prefix
+}
+
+// isManagedByUs reports whether a service was created by this tool.
+// The registry marker is authoritative; the name prefix is only a fallback for
+// services created before the marker existed.
+func (wsm *WindowsServiceManager) isManagedByUs(serviceName string) bool {
+	keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Services\%s\Parameters`, serviceName)
+	if k, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE); err == nil {
+		defer k.Close()
+		if marker, _, err := k.GetStringValue("ManagedBy"); err == nil {
+			return marker == managedByMarker
+		}
+	}
+
+	return wsm.namePrefix != "" && strings.HasPrefix(serviceName, wsm.namePrefix)
+}
+
+// saveServices saves service data to file; the caller holds wsm.mutex.
+// The data is written to a temporary file first and renamed over the data file, so a crash
+// mid-write never leaves a truncated file behind.
+func (wsm *WindowsServiceManager) saveServices() {
+	data, err := json.MarshalIndent(wsm.services, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if err := writeFileAtomic(wsm.dataFile, data); err != nil {
+		fmt.Printf("Warning: failed to save services: %v\n", err)
+	}
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the same directory
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// copyFile copies the content of src to a new file dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return nil
+}
+
+// loadServices loads service data from file.
+// A data file that cannot be read is kept as <file>.corrupt and the last good copy (<file>.bak)
+// is loaded instead; an error is returned only when no usable copy exists.
+func (wsm *WindowsServiceManager) loadServices() error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	backupFile := wsm.dataFile + ".bak"
+
+	services, err := readServicesFile(wsm.dataFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err == nil {
+		wsm.services = services
+		if err := copyFile(wsm.dataFile, backupFile); err != nil {
+			fmt.Printf("Warning: failed to back up services: %v\n", err)
+		}
+		return nil
+	}
+
+	loadErr := fmt.Errorf("failed to load services from %s: %v", wsm.dataFile, err)
+	if err := copyFile(wsm.dataFile, wsm.dataFile+".corrupt"); err != nil {
+		fmt.Printf("Warning: failed to keep corrupt data file: %v\n", err)
+	}
+
+	services, err = readServicesFile(backupFile)
+	if err != nil {
+		return loadErr
+	}
+
+	fmt.Printf("Warning: %v, restored the last good copy from %s\n", loadErr, backupFile)
+	wsm.services = services
+	wsm.saveServices()
+	return nil
+}
+
+// readServicesFile parses a services data file
+func readServicesFile(path string) (map[string]*Service, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]*Service)
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// SetServiceAutoStart sets whether a service starts automatically at boot
+func (wsm *WindowsServiceManager) SetServiceAutoStart(serviceID string, enabled bool) error {
+	if enabled {
+		return wsm.SetServiceStartType(serviceID, "auto")
+	}
+	return wsm.SetServiceStartType(serviceID, "manual")
+}
+
+// SetServiceStartType sets how a service is started: "auto", "delayed", "manual" or "disabled"
+func (wsm *WindowsServiceManager) SetServiceStartType(serviceID string, startType string) error {
+	var scmStartType uint32
+	delayed := false
+
+	switch startType {
+	case "auto":
+		scmStartType = mgr.StartAutomatic
+	case "delayed":
+		scmStartType = mgr.StartAutomatic
+		delayed = true
+	case "manual":
+		scmStartType = mgr.StartManual
+	case "disabled":
+		scmStartType = mgr.StartDisabled
+	default:
+		return fmt.Errorf("unknown start type: %s", startType)
+	}
+
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		// Get current service configuration
+		config, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+
+		// Modify start type
+		config.StartType = scmStartType
+		config.DelayedAutoStart = delayed
+
+		// Update service configuration
+		err = windowsService.UpdateConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to update service configuration: %v", err)
+		}
+
+		// Update in-memory service info
+		service.StartType = startType
+		service.AutoStart = scmStartType == mgr.StartAutomatic
+		service.UpdatedAt = time.Now()
+		wsm.saveServices()
+
+		return nil
+	})
+}
+
+// serviceDescription returns the SCM description for a new service
+func serviceDescription(config ServiceConfig) string {
+	if description := strings.TrimSpace(config.Description); description != "" {
+		return description
+	}
+	return fmt.Sprintf("Service created by Windows Service Manager: %s", config.Name)
+}
+
+// SetServiceDescription sets the description SCM shows for a service
+func (wsm *WindowsServiceManager) SetServiceDescription(serviceID, description string) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	description = strings.TrimSpace(description)
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		config, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+
+		config.Description = description
+		if err := windowsService.UpdateConfig(config); err != nil {
+			return wrapAccessError("failed to update service configuration", err)
+		}
+
+		service.Description = description
+		service.UpdatedAt = time.Now()
+		wsm.saveServices()
+		wsm.emitServicesUpdated()
+
+		return nil
+	})
+}
+
+// queryStartType reads the start type of a service from SCM
+func queryStartType(scm scmConnection, serviceName string) (string, error) {
+	windowsService, err := scm.OpenService(serviceName)
+	if err != nil {
+		return "", fmt.Errorf("failed to open service: %v", err)
+	}
+	defer windowsService.Close()
+
+	config, err := windowsService.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to get service configuration: %v", err)
+	}
+
+	return startTypeName(config), nil
+}
+
 // GetServiceAutoStart gets whether a service is set to auto-start
 func (wsm *WindowsServiceManager) GetServiceAutoStart(serviceID string) bool {
 	wsm.mutex.RLock()
@@ -632,8 +2274,813 @@ func (wsm *WindowsServiceManager) GetServiceAutoStart(serviceID string) bool {
 
 	service, exists := wsm.services[serviceID]
 	if !exists {
-		return false
+		return false
+	}
+
+	return service.AutoStart
+}
+
+// checkDependencies verifies that every dependency exists and is not the service itself
+func checkDependencies(scm scmConnection, serviceName string, dependencies []string) error {
+	for _, dependency := range dependencies {
+		if strings.EqualFold(dependency, serviceName) {
+			return fmt.Errorf("service cannot depend on itself")
+		}
+		// Load-order groups are prefixed with SC_GROUP_IDENTIFIER
+		if strings.HasPrefix(dependency, "+") {
+			continue
+		}
+		dependencyService, err := scm.OpenService(dependency)
+		if err != nil {
+			return fmt.Errorf("dependency does not exist: %s", dependency)
+		}
+		dependencyService.Close()
+	}
+	return nil
+}
+
+// SetServiceDependencies replaces the services that must be running before a service starts
+func (wsm *WindowsServiceManager) SetServiceDependencies(serviceID string, dependencies []string) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		if err := checkDependencies(scm, serviceID, dependencies); err != nil {
+			return err
+		}
+
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		config, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+
+		config.Dependencies = dependencies
+
+		err = windowsService.UpdateConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to update service configuration: %v", err)
+		}
+		if len(dependencies) == 0 {
+			if err := windowsService.ClearDependencies(); err != nil {
+				return fmt.Errorf("failed to clear service dependencies: %v", err)
+			}
+		}
+
+		service.Dependencies = dependencies
+		service.UpdatedAt = time.Now()
+		wsm.saveServices()
+
+		return nil
+	})
+}
+
+// builtinServiceAccounts are the accounts that run services without a password
+var builtinServiceAccounts = []string{
+	"LocalSystem",
+	`NT AUTHORITY\LocalService`,
+	`NT AUTHORITY\NetworkService`,
+}
+
+// validateServiceAccount checks that a service account is qualified and that a password
+// is only given together with an account
+func validateServiceAccount(account, password string) error {
+	if account == "" {
+		if password != "" {
+			return fmt.Errorf("a password requires an account such as .\\username or DOMAIN\\username")
+		}
+		return nil
+	}
+
+	for _, builtin := range builtinServiceAccounts {
+		if strings.EqualFold(account, builtin) {
+			if password != "" {
+				return fmt.Errorf("account %s does not take a password", account)
+			}
+			return nil
+		}
+	}
+
+	if !strings.Contains(account, `\`) && !strings.Contains(account, "@") {
+		return fmt.Errorf("account must be qualified, such as .\\%s or DOMAIN\\%s", account, account)
+	}
+
+	return nil
+}
+
+// validateLoadOrderGroup rejects group names that Windows cannot store.
+// Groups are user-defined, so any other name is accepted.
+func validateLoadOrderGroup(group string) error {
+	if group == "" {
+		return nil
+	}
+	if strings.TrimSpace(group) != group {
+		return fmt.Errorf("load order group must not start or end with whitespace")
+	}
+	if len(group) > 256 {
+		return fmt.Errorf("load order group must be at most 256 characters")
+	}
+	if strings.ContainsAny(group, `/\`) {
+		return fmt.Errorf("load order group must not contain slashes: %s", group)
+	}
+	for _, r := range group {
+		if r < 0x20 {
+			return fmt.Errorf("load order group must not contain control characters")
+		}
+	}
+	return nil
+}
+
+// SetServiceLoadOrderGroup changes the load-order group a service starts in
+func (wsm *WindowsServiceManager) SetServiceLoadOrderGroup(serviceID, group string) error {
+	if err := validateLoadOrderGroup(group); err != nil {
+		return err
+	}
+
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		config, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+
+		config.LoadOrderGroup = group
+
+		err = windowsService.UpdateConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to update service configuration: %v", err)
+		}
+
+		service.LoadOrderGroup = group
+		service.UpdatedAt = time.Now()
+		wsm.saveServices()
+
+		return nil
+	})
+}
+
+// ServiceDetails is the live configuration of a service as reported by SCM
+type ServiceDetails struct {
+	ID             string           `json:"id"`
+	DisplayName    string           `json:"displayName"`
+	BinaryPath     string           `json:"binaryPath"`
+	StartType      string           `json:"startType"`
+	LoadOrderGroup string           `json:"loadOrderGroup"`
+	Account        string           `json:"account"`
+	Dependencies   []string         `json:"dependencies"`
+	Description    string           `json:"description"`
+	Delayed        bool             `json:"delayed"`
+	Triggers       []ServiceTrigger `json:"triggers"`
+	Status         string           `json:"status"`
+	PID            int              `json:"pid"`
+}
+
+// ServiceTrigger describes an event that starts or stops a service
+type ServiceTrigger struct {
+	Type    string `json:"type"`
+	Action  string `json:"action"`
+	Subtype string `json:"subtype"`
+}
+
+// serviceTriggerInfo mirrors the Win32 SERVICE_TRIGGER_INFO structure
+type serviceTriggerInfo struct {
+	count    uint32
+	triggers *serviceTrigger
+	reserved *byte
+}
+
+// serviceTrigger mirrors the Win32 SERVICE_TRIGGER structure
+type serviceTrigger struct {
+	triggerType uint32
+	action      uint32
+	subtype     *windows.GUID
+	dataCount   uint32
+	dataItems   uintptr
+}
+
+// serviceTriggerTypeNames maps SERVICE_TRIGGER_TYPE_* values to readable names
+var serviceTriggerTypeNames = map[uint32]string{
+	1:  "device-arrival",
+	2:  "ip-address",
+	3:  "domain-join",
+	4:  "firewall-port",
+	5:  "group-policy",
+	6:  "network-endpoint",
+	20: "custom",
+}
+
+// queryServiceTriggers reads the trigger-start configuration of a service
+func queryServiceTriggers(windowsService scmService) ([]ServiceTrigger, error) {
+	n := uint32(1024)
+	var b []byte
+	for {
+		b = make([]byte, n)
+		err := windows.QueryServiceConfig2(windowsService.Handle(), windows.SERVICE_CONFIG_TRIGGER_INFO, &b[0], n, &n)
+		if err == nil {
+			break
+		}
+		if err != windows.ERROR_INSUFFICIENT_BUFFER || n <= uint32(len(b)) {
+			return nil, err
+		}
+	}
+
+	info := (*serviceTriggerInfo)(unsafe.Pointer(&b[0]))
+	triggers := make([]ServiceTrigger, 0, info.count)
+	if info.count == 0 || info.triggers == nil {
+		return triggers, nil
+	}
+
+	for _, t := range unsafe.Slice(info.triggers, info.count) {
+		name, ok := serviceTriggerTypeNames[t.triggerType]
+		if !ok {
+			name = fmt.Sprintf("type-%d", t.triggerType)
+		}
+		action := "start"
+		if t.action == 2 {
+			action = "stop"
+		}
+		subtype := ""
+		if t.subtype != nil {
+			subtype = t.subtype.String()
+		}
+		triggers = append(triggers, ServiceTrigger{Type: name, Action: action, Subtype: subtype})
+	}
+
+	return triggers, nil
+}
+
+// startTypeName converts an SCM start type into the name used by the frontend
+func startTypeName(config mgr.Config) string {
+	switch config.StartType {
+	case mgr.StartAutomatic:
+		if config.DelayedAutoStart {
+			return "delayed"
+		}
+		return "auto"
+	case mgr.StartManual:
+		return "manual"
+	case mgr.StartDisabled:
+		return "disabled"
+	case windows.SERVICE_BOOT_START:
+		return "boot"
+	case windows.SERVICE_SYSTEM_START:
+		return "system"
+	default:
+		return "unknown"
+	}
+}
+
+// GetServiceDetails reads the live SCM configuration of a managed service
+func (wsm *WindowsServiceManager) GetServiceDetails(serviceID string) (*ServiceDetails, error) {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	if _, exists := wsm.services[serviceID]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	var details *ServiceDetails
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		config, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+
+		triggers, err := queryServiceTriggers(windowsService)
+		if err != nil {
+			triggers = []ServiceTrigger{}
+		}
+
+		status, pid := wsm.getServiceRealTimeStatus(scm, serviceID)
+
+		details = &ServiceDetails{
+			ID:             serviceID,
+			DisplayName:    config.DisplayName,
+			BinaryPath:     config.BinaryPathName,
+			StartType:      startTypeName(config),
+			LoadOrderGroup: config.LoadOrderGroup,
+			Account:        config.ServiceStartName,
+			Dependencies:   config.Dependencies,
+			Description:    config.Description,
+			Delayed:        config.DelayedAutoStart,
+			Triggers:       triggers,
+			Status:         status,
+			PID:            pid,
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// FullServiceConfig is everything the edit dialog needs about a service in one place
+type FullServiceConfig struct {
+	ID          string          `json:"id"`
+	Config      ServiceConfig   `json:"config"` // editable settings; Password is always empty
+	StartType   string          `json:"startType"`
+	BinaryPath  string          `json:"binaryPath"`
+	Description string          `json:"description"`
+	Recovery    *RecoveryConfig `json:"recovery"` // nil when the recovery actions could not be read
+	Critical    bool            `json:"critical"`
+	Adopted     bool            `json:"adopted"`
+	Tags        []string        `json:"tags"`
+	Status      string          `json:"status"`
+	PID         int             `json:"pid"`
+}
+
+// GetServiceConfig combines the stored service, its live SCM configuration and its wrapper
+// parameters into one configuration
+func (wsm *WindowsServiceManager) GetServiceConfig(serviceID string) (*FullServiceConfig, error) {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
 	}
 
-	return service.AutoStart
-}
\ No newline at end of file
+	full := &FullServiceConfig{
+		ID: serviceID,
+		Config: ServiceConfig{
+			Name:         service.Name,
+			ExePath:      service.ExePath,
+			Args:         service.Args,
+			WorkingDir:   service.WorkingDir,
+			LogPath:      service.LogPath,
+			ErrorLogPath: service.ErrorLogPath,
+			Tags:         service.Tags,
+		},
+		Critical: service.Critical,
+		Adopted:  service.Adopted,
+		Tags:     service.Tags,
+	}
+
+	// Adopted services have no wrapper parameters, so their stored values are kept
+	if !service.Adopted {
+		if config, err := LoadServiceConfigFromRegistry(serviceID); err == nil {
+			config.Name = service.Name
+			full.Config = *config
+		}
+	}
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		config, err := windowsService.Config()
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %v", err)
+		}
+
+		full.Config.Account = config.ServiceStartName
+		full.Config.Dependencies = config.Dependencies
+		full.Config.LoadOrderGroup = config.LoadOrderGroup
+		full.StartType = startTypeName(config)
+		full.BinaryPath = config.BinaryPathName
+		full.Description = config.Description
+
+		if recovery, err := queryRecoveryConfig(windowsService); err == nil {
+			full.Recovery = recovery
+		}
+
+		full.Status, full.PID = wsm.getServiceRealTimeStatus(scm, serviceID)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return full, nil
+}
+
+// queryRecoveryConfig reads the recovery actions of a service from SCM
+func queryRecoveryConfig(windowsService scmService) (*RecoveryConfig, error) {
+	actions, err := windowsService.RecoveryActions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery actions: %v", err)
+	}
+	resetPeriod, err := windowsService.ResetPeriod()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reset period: %v", err)
+	}
+
+	config := &RecoveryConfig{
+		ResetPeriodSec: int(resetPeriod),
+		Actions:        make([]RecoveryAction, 0, len(actions)),
+	}
+	for _, action := range actions {
+		actionType := "none"
+		switch action.Type {
+		case mgr.ServiceRestart:
+			actionType = "restart"
+		case mgr.ComputerReboot:
+			actionType = "reboot"
+		}
+		config.Actions = append(config.Actions, RecoveryAction{
+			Type:     actionType,
+			DelaySec: int(action.Delay / time.Second),
+		})
+	}
+	return config, nil
+}
+
+// SetServiceCritical marks a service as critical so stopping or deleting it needs confirmation
+func (wsm *WindowsServiceManager) SetServiceCritical(serviceID string, critical bool) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	service.Critical = critical
+	service.UpdatedAt = time.Now()
+	wsm.saveServices()
+
+	return nil
+}
+
+// RecoveryAction is one step of a service's failure recovery
+type RecoveryAction struct {
+	Type     string `json:"type"`     // "none", "restart" or "reboot"
+	DelaySec int    `json:"delaySec"` // delay before the action is taken
+}
+
+// RecoveryConfig describes what SCM does when a service fails.
+// Actions apply to the first, second and subsequent failures; the failure count resets after ResetPeriodSec.
+type RecoveryConfig struct {
+	ResetPeriodSec int              `json:"resetPeriodSec"`
+	Actions        []RecoveryAction `json:"actions"`
+}
+
+// toRecoveryActions converts a RecoveryConfig into SCM recovery actions
+func (config RecoveryConfig) toRecoveryActions() ([]mgr.RecoveryAction, error) {
+	if len(config.Actions) > 3 {
+		return nil, fmt.Errorf("at most 3 recovery actions are supported")
+	}
+	if config.ResetPeriodSec < 0 {
+		return nil, fmt.Errorf("reset period cannot be negative")
+	}
+
+	actions := make([]mgr.RecoveryAction, 0, len(config.Actions))
+	for _, action := range config.Actions {
+		if action.DelaySec < 0 {
+			return nil, fmt.Errorf("recovery delay cannot be negative")
+		}
+
+		var actionType int
+		switch action.Type {
+		case "none", "":
+			actionType = mgr.NoAction
+		case "restart":
+			actionType = mgr.ServiceRestart
+		case "reboot":
+			actionType = mgr.ComputerReboot
+		default:
+			return nil, fmt.Errorf("unknown recovery action: %s", action.Type)
+		}
+
+		actions = append(actions, mgr.RecoveryAction{
+			Type:  actionType,
+			Delay: time.Duration(action.DelaySec) * time.Second,
+		})
+	}
+
+	return actions, nil
+}
+
+// SetServiceRecovery configures the failure recovery actions of a service
+func (wsm *WindowsServiceManager) SetServiceRecovery(serviceID string, config RecoveryConfig) error {
+	actions, err := config.toRecoveryActions()
+	if err != nil {
+		return err
+	}
+
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	if _, exists := wsm.services[serviceID]; !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	return wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %v", err)
+		}
+		defer windowsService.Close()
+
+		if len(actions) == 0 {
+			if err := windowsService.ResetRecoveryActions(); err != nil {
+				return fmt.Errorf("failed to reset recovery actions: %v", err)
+			}
+			return nil
+		}
+
+		if err := windowsService.SetRecoveryActions(actions, uint32(config.ResetPeriodSec)); err != nil {
+			return fmt.Errorf("failed to set recovery actions: %v", err)
+		}
+		// The wrapper reports a failed target as a stop with an exit code, not a crash,
+		// so the actions only run if SCM also applies them to non-crash failures
+		if err := windowsService.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+			return fmt.Errorf("failed to enable recovery actions on non-crash failures: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// requireConfirmation refuses an action on a critical service and asks the frontend to confirm it
+func (wsm *WindowsServiceManager) requireConfirmation(serviceID, action string) error {
+	wsm.mutex.RLock()
+	service, exists := wsm.services[serviceID]
+	critical := exists && service.Critical
+	wsm.mutex.RUnlock()
+
+	if !critical {
+		return nil
+	}
+
+	token := wsm.confirmations.issue(serviceID, action)
+	if wsm.ctx != nil {
+		runtime.EventsEmit(wsm.ctx, "critical-action-requested", map[string]interface{}{
+			"serviceId": serviceID,
+			"action":    action,
+			"token":     token,
+		})
+	}
+
+	return fmt.Errorf("service %s is critical, the %s action must be confirmed", serviceID, action)
+}
+
+// ConfirmCriticalAction performs an action on a critical service previously refused by requireConfirmation.
+// It returns the confirmed action so the caller can follow up on it.
+func (wsm *WindowsServiceManager) ConfirmCriticalAction(token string) (pendingAction, error) {
+	pending, ok := wsm.confirmations.consume(token)
+	if !ok {
+		return pendingAction{}, fmt.Errorf("confirmation token is invalid or expired")
+	}
+
+	switch pending.action {
+	case "stop":
+		return pending, wsm.stopService(pending.serviceID)
+	case "force-stop":
+		return pending, wsm.forceStopService(pending.serviceID)
+	case "restart":
+		return pending, wsm.restartService(pending.serviceID)
+	case "reload":
+		return pending, wsm.reloadService(pending.serviceID)
+	case "delete":
+		return pending, wsm.deleteService(pending.serviceID, false, false)
+	case "delete-non-wrapper":
+		return pending, wsm.deleteService(pending.serviceID, false, true)
+	case "force-delete":
+		return pending, wsm.deleteService(pending.serviceID, true, false)
+	default:
+		return pending, fmt.Errorf("unknown action: %s", pending.action)
+	}
+}
+
+// RegistryRepair describes one registry value rewritten by RepairServiceRegistry
+type RegistryRepair struct {
+	Value    string `json:"value"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// RepairReport lists what RepairServiceRegistry changed
+type RepairReport struct {
+	ServiceID string           `json:"serviceId"`
+	Repaired  []RegistryRepair `json:"repaired"`
+}
+
+// refreshUptime updates the uptime of a service. When the start was not observed by the manager
+// (adopted or externally started services), the creation time of its process is used.
+func (wsm *WindowsServiceManager) refreshUptime(service *Service) {
+	if service.Status != "running" || service.PID == 0 {
+		service.StartedAt = time.Time{}
+		service.UptimeSec = 0
+		return
+	}
+
+	if service.StartedAt.IsZero() {
+		if startedAt, err := processCreationTime(service.PID); err == nil {
+			service.StartedAt = startedAt
+		}
+	}
+	if !service.StartedAt.IsZero() {
+		service.UptimeSec = int64(time.Since(service.StartedAt) / time.Second)
+	}
+}
+
+// GetServiceUptime returns how long a service has been running, or zero if it is not running
+func (wsm *WindowsServiceManager) GetServiceUptime(serviceID string) (time.Duration, error) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return 0, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		service.Status, service.PID = wsm.getServiceRealTimeStatus(scm, serviceID)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	wsm.refreshUptime(service)
+	if service.StartedAt.IsZero() {
+		return 0, nil
+	}
+	return time.Since(service.StartedAt), nil
+}
+
+// loadLastExit fills in the exit code and time the wrapper recorded for the target's last run
+func loadLastExit(service *Service) {
+	values := readServiceParameters(service.ID, "LastExitCode", "LastExitTime")
+
+	if value, ok := values["LastExitCode"]; ok {
+		if code, err := strconv.Atoi(value); err == nil {
+			service.LastExitCode = &code
+		}
+	}
+	if value, ok := values["LastExitTime"]; ok {
+		if exitTime, err := time.Parse(time.RFC3339, value); err == nil {
+			service.LastExitTime = exitTime
+		}
+	}
+}
+
+// readServiceParameters reads the string values of a service's Parameters key.
+// Missing values are absent from the returned map.
+func readServiceParameters(serviceName string, names ...string) map[string]string {
+	values := make(map[string]string)
+
+	keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Services\%s\Parameters`, serviceName)
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return values
+	}
+	defer k.Close()
+
+	for _, name := range names {
+		if value, _, err := k.GetStringValue(name); err == nil {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// repairedParameterNames are the Parameters values RepairServiceRegistry checks and rewrites
+var repairedParameterNames = []string{"ManagedBy", "ExePath", "Args", "ArgsList", "WorkingDir", "AppDirectory", "StdoutLog", "StderrLog"}
+
+// applyRegistryRepairs writes the repaired values through set, deleting those that should be empty
+func applyRegistryRepairs(repairs []RegistryRepair, set func(name, value string) error, del func(name string) error) error {
+	for _, repair := range repairs {
+		if repair.Current == "" {
+			if err := del(repair.Value); err != nil {
+				return fmt.Errorf("failed to clear %s: %w", repair.Value, err)
+			}
+			continue
+		}
+		if err := set(repair.Value, repair.Current); err != nil {
+			return fmt.Errorf("failed to set %s: %w", repair.Value, err)
+		}
+	}
+	return nil
+}
+
+// RepairServiceRegistry compares the registry Parameters and ImagePath of a service with
+// the stored configuration and rewrites anything missing or divergent
+func (wsm *WindowsServiceManager) RepairServiceRegistry(serviceID string) (RepairReport, error) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	report := RepairReport{ServiceID: serviceID, Repaired: []RegistryRepair{}}
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return report, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if service.Adopted {
+		return report, fmt.Errorf("service %s is not run by the built-in wrapper", serviceID)
+	}
+
+	current := readServiceParameters(serviceID, repairedParameterNames...)
+
+	logPath := service.LogPath
+	if logPath == "" {
+		logPath = current["StdoutLog"]
+	}
+	if logPath == "" {
+		logPath = wsm.defaultLogPath(serviceID)
+	}
+
+	config := ServiceConfig{
+		Name:         service.Name,
+		ExePath:      service.ExePath,
+		Args:         service.Args,
+		WorkingDir:   service.WorkingDir,
+		LogPath:      logPath,
+		ErrorLogPath: service.ErrorLogPath,
+	}
+	if err := normalizeArgs(&config); err != nil {
+		return report, err
+	}
+
+	var argsList string
+	if len(config.ArgsList) > 0 {
+		encoded, err := json.Marshal(config.ArgsList)
+		if err != nil {
+			return report, fmt.Errorf("failed to encode ArgsList: %v", err)
+		}
+		argsList = string(encoded)
+	}
+
+	expected := map[string]string{
+		"ManagedBy":    managedByMarker,
+		"ExePath":      config.ExePath,
+		"Args":         config.Args,
+		"ArgsList":     argsList,
+		"WorkingDir":   config.WorkingDir,
+		"AppDirectory": config.WorkingDir,
+		"StdoutLog":    config.LogPath,
+		"StderrLog":    stderrLogPath(config),
+	}
+
+	for _, name := range repairedParameterNames {
+		value, found := current[name]
+		if value == expected[name] && (found || expected[name] == "") {
+			continue
+		}
+		report.Repaired = append(report.Repaired, RegistryRepair{Value: name, Previous: value, Current: expected[name]})
+	}
+
+	// Only the divergent values are rewritten, everything else in Parameters is left as it is
+	err := applyRegistryRepairs(report.Repaired,
+		func(name, value string) error {
+			return wsm.setServiceRegistryValue(serviceID, "Parameters", name, value)
+		},
+		func(name string) error {
+			return wsm.deleteServiceRegistryValue(serviceID, "Parameters", name)
+		})
+	if err != nil {
+		return report, err
+	}
+
+	expectedImagePath, err := wrapperImagePath(serviceID)
+	if err != nil {
+		return report, err
+	}
+	imagePath, _ := readServiceImagePath(serviceID)
+	if imagePath != expectedImagePath {
+		if err := wsm.setServiceImagePathDirect(serviceID, expectedImagePath); err != nil {
+			return report, err
+		}
+		report.Repaired = append(report.Repaired, RegistryRepair{Value: "ImagePath", Previous: imagePath, Current: expectedImagePath})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func TestApplyRegistryRepairsKeepsUnrelatedValues(t *testing.T) {
+	params := map[string]string{
+		"ExePath":        `C:\old\app.exe`,
+		"Args":           "--stale",
+		"Env":            `{"MODE":"prod"}`,
+		"ExtraCommands":  `[{"exePath":"C:\\tools\\sidecar.exe"}]`,
+		"ListenPort":     "8080",
+		"LogEncoding":    "shift_jis",
+		"LogMaxSizeMB":   "50",
+		"RestartOnExit":  "true",
+		"StopTimeoutSec": "30",
+	}
+
+	repairs := []RegistryRepair{
+		{Value: "ExePath", Previous: `C:\old\app.exe`, Current: `C:\new\app.exe`},
+		{Value: "Args", Previous: "--stale", Current: ""},
+		{Value: "ManagedBy", Previous: "", Current: managedByMarker},
+	}
+
+	err := applyRegistryRepairs(repairs,
+		func(name, value string) error {
+			params[name] = value
+			return nil
+		},
+		func(name string) error {
+			delete(params, name)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("applyRegistryRepairs: %v", err)
+	}
+
+	if params["ExePath"] != `C:\new\app.exe` {
+		t.Errorf("ExePath = %q, want the repaired path", params["ExePath"])
+	}
+	if _, found := params["Args"]; found {
+		t.Errorf("Args should have been cleared, got %q", params["Args"])
+	}
+	if params["ManagedBy"] != managedByMarker {
+		t.Errorf("ManagedBy = %q, want %q", params["ManagedBy"], managedByMarker)
+	}
+
+	unrelated := map[string]string{
+		"Env":            `{"MODE":"prod"}`,
+		"ExtraCommands":  `[{"exePath":"C:\\tools\\sidecar.exe"}]`,
+		"ListenPort":     "8080",
+		"LogEncoding":    "shift_jis",
+		"LogMaxSizeMB":   "50",
+		"RestartOnExit":  "true",
+		"StopTimeoutSec": "30",
+	}
+	for name, want := range unrelated {
+		if got := params[name]; got != want {
+			t.Errorf("%s = %q after repair, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSetServiceDependencies(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	wsm := newTestManager(t, newFakeConnector(fake, newFakeService("Tcpip", svc.Running), newFakeService("Dnscache", svc.Running)))
+	service := addTestService(wsm, "WSM_app", "stopped")
+
+	if err := wsm.SetServiceDependencies("WSM_app", []string{"Tcpip", "Dnscache"}); err != nil {
+		t.Fatalf("SetServiceDependencies: %v", err)
+	}
+	if got := fake.config.Dependencies; len(got) != 2 {
+		t.Fatalf("SCM dependencies = %v, want Tcpip and Dnscache", got)
+	}
+
+	if err := wsm.SetServiceDependencies("WSM_app", []string{}); err != nil {
+		t.Fatalf("SetServiceDependencies with no dependencies: %v", err)
+	}
+	if got := fake.config.Dependencies; len(got) != 0 {
+		t.Errorf("SCM dependencies = %v after clearing, want none", got)
+	}
+	if len(service.Dependencies) != 0 {
+		t.Errorf("service dependencies = %v after clearing, want none", service.Dependencies)
+	}
+}
+
+func TestSetServiceDependenciesRejectsMissingDependency(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	addTestService(wsm, "WSM_app", "stopped")
+
+	if err := wsm.SetServiceDependencies("WSM_app", []string{"NoSuchService"}); err == nil {
+		t.Errorf("SetServiceDependencies accepted a dependency that does not exist")
+	}
+}
+
+// TestManagerConcurrentAccess hammers the manager from many goroutines.
+// It is meant to be run with -race, which reports any unsynchronized access to the service map.
+func TestManagerConcurrentAccess(t *testing.T) {
+	app := newFakeService("WSM_app", svc.Stopped)
+	app.startStates = []svc.State{svc.Running}
+	app.stopStates = []svc.State{svc.Stopped}
+	connector := newFakeConnector(app)
+
+	const external = 8
+	for i := 0; i < external; i++ {
+		service := newFakeService(fmt.Sprintf("Ext_%d", i), svc.Running)
+		service.config.DisplayName = service.name
+		service.config.BinaryPathName = `C:\ext\ext.exe --serve`
+		connector.services[service.name] = service
+	}
+
+	wsm := newTestManager(t, connector)
+	addTestService(wsm, "WSM_app", "stopped")
+
+	const iterations = 50
+	var wg sync.WaitGroup
+	run := func(work func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				work(i)
+			}
+		}()
+	}
+
+	// Adopting and releasing adds and removes map entries, like create and delete
+	for g := 0; g < external; g++ {
+		name := fmt.Sprintf("Ext_%d", g)
+		run(func(i int) {
+			if err := wsm.AdoptService(name); err != nil {
+				t.Errorf("AdoptService(%s): %v", name, err)
+				return
+			}
+			if err := wsm.ReleaseService(name); err != nil {
+				t.Errorf("ReleaseService(%s): %v", name, err)
+			}
+		})
+	}
+
+	run(func(i int) {
+		if i%2 == 0 {
+			if err := wsm.StartService("WSM_app"); err != nil && !errors.Is(err, ErrServiceAlreadyRunning) {
+				t.Errorf("StartService: %v", err)
+			}
+		} else if err := wsm.stopService("WSM_app"); err != nil {
+			t.Errorf("stopService: %v", err)
+		}
+	})
+	run(func(i int) {
+		if _, err := wsm.GetServices(); err != nil {
+			t.Errorf("GetServices: %v", err)
+		}
+	})
+	run(func(i int) {
+		if _, err := wsm.QueryServices(ServiceFilter{}); err != nil {
+			t.Errorf("QueryServices: %v", err)
+		}
+	})
+	run(func(i int) {
+		wsm.pollServiceStatuses()
+	})
+	run(func(i int) {
+		if _, _, err := wsm.GetServiceLogPath("WSM_app"); err != nil {
+			t.Errorf("GetServiceLogPath: %v", err)
+		}
+		wsm.statusCache.Invalidate("WSM_app")
+	})
+
+	wg.Wait()
+
+	if len(wsm.services) != 1 {
+		t.Errorf("%d services managed after the run, want only WSM_app", len(wsm.services))
+	}
+}
+
+func TestExpandServicePath(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("TEMP", temp)
+	t.Setenv("WSM_TEST_APP", "my app")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`%TEMP%\app.exe`, filepath.Join(temp, "app.exe")},
+		{`"%TEMP%\app.exe"`, filepath.Join(temp, "app.exe")},
+		{`%temp%\%WSM_TEST_APP%\app.exe`, filepath.Join(temp, "my app", "app.exe")},
+		{`C:\tools\app.exe`, `C:\tools\app.exe`},
+		{`%WSM_TEST_UNDEFINED%\app.exe`, `%WSM_TEST_UNDEFINED%\app.exe`},
+	}
+
+	for _, tt := range tests {
+		if got := expandServicePath(tt.path); got != tt.want {
+			t.Errorf("expandServicePath(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestServiceWorkingDirExpandsTemp(t *testing.T) {
+	temp := t.TempDir()
+	t.Setenv("TEMP", temp)
+	exePath := `%TEMP%\app\app.exe`
+
+	tests := []struct {
+		workingDir string
+		want       string
+	}{
+		{"", filepath.Join(temp, "app")},
+		{`%TEMP%\work`, filepath.Join(temp, "work")},
+		{`data`, filepath.Join(temp, "app", "data")},
+	}
+
+	for _, tt := range tests {
+		if got := serviceWorkingDir(tt.workingDir, exePath); got != tt.want {
+			t.Errorf("serviceWorkingDir(%q) = %s, want %s", tt.workingDir, got, tt.want)
+		}
+	}
+}
+
+func TestSetServiceRecoveryAppliesToNonCrashFailures(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	addTestService(wsm, "WSM_app", "stopped")
+
+	config := RecoveryConfig{
+		ResetPeriodSec: 86400,
+		Actions:        []RecoveryAction{{Type: "restart", DelaySec: 5}},
+	}
+	if err := wsm.SetServiceRecovery("WSM_app", config); err != nil {
+		t.Fatalf("SetServiceRecovery: %v", err)
+	}
+
+	if len(fake.recoveryActions) != 1 || fake.recoveryActions[0].Type != mgr.ServiceRestart {
+		t.Errorf("recovery actions = %v, want a single restart", fake.recoveryActions)
+	}
+	// The wrapper stops itself with an exit code when the target fails, which SCM treats as a non-crash failure
+	if !fake.nonCrashFailures {
+		t.Errorf("recovery actions do not apply to non-crash failures")
+	}
+}
+
+func TestEditedServiceConfigKeepsOtherSettings(t *testing.T) {
+	backups := 5
+	current := ServiceConfig{
+		ExePath:                `C:\old\app.exe`,
+		Args:                   "--old",
+		ArgsList:               []string{"--old"},
+		WorkingDir:             `C:\old`,
+		LogPath:                `C:\logs\app.log`,
+		LogMaxSizeMB:           50,
+		LogMaxBackups:          &backups,
+		ListenPort:             8080,
+		StopTimeoutSec:         30,
+		RestartOnExit:          true,
+		MaxRestarts:            4,
+		Env:                    map[string]string{"MODE": "prod"},
+		ExtraCommands:          []CommandSpec{{ExePath: `C:\tools\sidecar.exe`}},
+		HealthCheckType:        "tcp",
+		HealthCheckTarget:      "localhost:8080",
+		HealthCheckIntervalSec: 15,
+	}
+	edit := ServiceConfig{
+		ExePath:  `%TEMP%\new\app.exe`,
+		Args:     "--new",
+		ArgsList: []string{"--new"},
+	}
+
+	merged := editedServiceConfig(current, edit)
+
+	if merged.ExePath != edit.ExePath || merged.Args != "--new" || len(merged.ArgsList) != 1 || merged.ArgsList[0] != "--new" {
+		t.Errorf("executable and arguments were not taken from the edit: %+v", merged)
+	}
+	if merged.WorkingDir != `%TEMP%\new` {
+		t.Errorf("WorkingDir = %s, want the directory of the new executable", merged.WorkingDir)
+	}
+
+	merged.ExePath, merged.Args, merged.ArgsList, merged.WorkingDir = current.ExePath, current.Args, current.ArgsList, current.WorkingDir
+	if !reflect.DeepEqual(merged, current) {
+		t.Errorf("edit changed settings it does not cover:\ngot  %+v\nwant %+v", merged, current)
+	}
+}
+
+func TestDeleteServicesSkipsRunningServices(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	addTestService(wsm, "WSM_app", "running")
+
+	results := wsm.DeleteServices([]string{"WSM_app"})
+
+	if !strings.Contains(results["WSM_app"], "requires confirmation") {
+		t.Errorf("result = %q, want a request for confirmation", results["WSM_app"])
+	}
+	if fake.deleted || len(fake.controls) != 0 {
+		t.Errorf("a running service was stopped or deleted without a token")
+	}
+	if _, exists := wsm.services["WSM_app"]; !exists {
+		t.Errorf("the service was removed from the managed services")
+	}
+}
+
+func TestGetServicesSavesOnlyWhenSomethingChanged(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	addTestService(wsm, "WSM_app", "stopped")
+
+	if _, err := wsm.GetServices(); err != nil {
+		t.Fatalf("GetServices: %v", err)
+	}
+	if _, err := os.Stat(wsm.dataFile); err != nil {
+		t.Fatalf("data file was not written after the status changed: %v", err)
+	}
+
+	if err := os.Remove(wsm.dataFile); err != nil {
+		t.Fatalf("failed to remove data file: %v", err)
+	}
+	if _, err := wsm.GetServices(); err != nil {
+		t.Fatalf("GetServices: %v", err)
+	}
+	if _, err := os.Stat(wsm.dataFile); !os.IsNotExist(err) {
+		t.Errorf("data file was written again although nothing changed")
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// toastProtocol is the URL protocol a crash toast launches when clicked. It starts the app,
+// and the single instance lock turns that into showing the running instance's window.
+const toastProtocol = "windows-service-manager"
+
+// toastAppID shows toasts under Windows PowerShell, which is registered on every system
+const toastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// toastScript builds and shows the toast. Title and message come from the environment so
+// service names never end up inside the script itself.
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$title = [System.Security.SecurityElement]::Escape($env:WSM_TOAST_TITLE)
+$message = [System.Security.SecurityElement]::Escape($env:WSM_TOAST_MESSAGE)
+$launch = [System.Security.SecurityElement]::Escape($env:WSM_TOAST_LAUNCH)
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml("<toast activationType=""protocol"" launch=""$launch""><visual><binding template=""ToastGeneric""><text>$title</text><text>$message</text></binding></visual></toast>")
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:WSM_TOAST_APPID).Show($toast)
+`
+
+// SetNotifyOnCrash sets whether the status watcher shows a toast when a service stops unexpectedly
+func (wsm *WindowsServiceManager) SetNotifyOnCrash(enabled bool) {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	wsm.notifyOnCrash = enabled
+}
+
+// stoppedUnexpectedly reports whether a service last reported as running or starting has gone down.
+// Stops made through the app report the stopped status themselves, so a service the watcher
+// still has as running went down on its own. service.Status cannot tell this apart, since
+// GetServices refreshes it without reporting the change.
+func stoppedUnexpectedly(last watchedStatus, status string) bool {
+	return (last.status == "running" || last.status == "starting") &&
+		(status == "stopped" || status == "error")
+}
+
+// notifyServiceCrashed shows a toast for a service that stopped without the app stopping it
+func notifyServiceCrashed(service *Service) {
+	message := fmt.Sprintf("%s (%s) stopped unexpectedly", service.Name, service.ID)
+	if service.LastExitCode != nil {
+		message += fmt.Sprintf(" with exit code %d", *service.LastExitCode)
+	}
+
+	go func() {
+		if err := showToast("Service crashed", message); err != nil {
+			fmt.Printf("Warning: failed to show crash notification: %v\n", err)
+		}
+	}()
+}
+
+// showToast shows a Windows toast notification that brings up the main window when clicked
+func showToast(title, message string) error {
+	if err := registerToastProtocol(); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", "-")
+	cmd.Env = append(os.Environ(),
+		"WSM_TOAST_TITLE="+title,
+		"WSM_TOAST_MESSAGE="+message,
+		"WSM_TOAST_LAUNCH="+toastProtocol+":show",
+		"WSM_TOAST_APPID="+toastAppID,
+	)
+	cmd.Stdin = strings.NewReader(toastScript)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow: true,
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("powershell failed: %v: %s", err, output)
+	}
+	return nil
+}
+
+// registerToastProtocol points the toast protocol at the current executable for the current user
+func registerToastProtocol() error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %v", err)
+	}
+
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, `Software\Classes\`+toastProtocol, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to register toast protocol: %v", err)
+	}
+	defer key.Close()
+
+	if err := key.SetStringValue("", "URL:Windows Service Manager"); err != nil {
+		return fmt.Errorf("failed to register toast protocol: %v", err)
+	}
+	if err := key.SetStringValue("URL Protocol", ""); err != nil {
+		return fmt.Errorf("failed to register toast protocol: %v", err)
+	}
+
+	command, _, err := registry.CreateKey(key, `shell\open\command`, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to register toast protocol: %v", err)
+	}
+	defer command.Close()
+
+	return command.SetStringValue("", fmt.Sprintf("\"%s\"", exePath))
+}
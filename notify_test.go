@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestStoppedUnexpectedly(t *testing.T) {
+	tests := []struct {
+		last    string
+		current string
+		want    bool
+	}{
+		{"running", "stopped", true},
+		{"running", "error", true},
+		{"starting", "stopped", true},
+		{"running", "running", false},
+		{"stopped", "stopped", false},
+		{"stopping", "stopped", false},
+		{"paused", "stopped", false},
+		{"stopped", "running", false},
+	}
+
+	for _, tt := range tests {
+		last := watchedStatus{status: tt.last}
+		if got := stoppedUnexpectedly(last, tt.current); got != tt.want {
+			t.Errorf("stoppedUnexpectedly(%s -> %s) = %v, want %v", tt.last, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestCrashDetectedAfterGetServicesRefresh(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "running")
+	service.PID = fakeServicePID
+	wsm.seedWatchedStatuses()
+
+	// GetServices sees the crash first and overwrites service.Status
+	fake.status = fakeStatus(svc.Stopped, 0)
+	if _, err := wsm.GetServices(); err != nil {
+		t.Fatalf("GetServices: %v", err)
+	}
+
+	if !stoppedUnexpectedly(wsm.lastWatchedStatus(service), service.Status) {
+		t.Errorf("crash of a service refreshed by GetServices was not detected")
+	}
+}
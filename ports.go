@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modiphlpapi             = windows.NewLazySystemDLL("iphlpapi.dll")
+	procGetExtendedTcpTable = modiphlpapi.NewProc("GetExtendedTcpTable")
+)
+
+// TCP_TABLE_OWNER_PID_LISTENER asks GetExtendedTcpTable for listening sockets with their owning PID
+const tcpTableOwnerPIDListener = 3
+
+// tcpTableLayout describes the rows of MIB_TCPTABLE_OWNER_PID and MIB_TCP6TABLE_OWNER_PID
+type tcpTableLayout struct {
+	family     uint32
+	rowSize    int
+	portOffset int
+	pidOffset  int
+}
+
+var tcpTableLayouts = []tcpTableLayout{
+	{family: windows.AF_INET, rowSize: 24, portOffset: 8, pidOffset: 20},
+	{family: windows.AF_INET6, rowSize: 56, portOffset: 20, pidOffset: 52},
+}
+
+// checkPortAvailable returns ErrPortInUse when something already listens on TCP port,
+// naming the process that holds it when it can be found
+func checkPortAvailable(port int) error {
+	if pid, ok := listeningPortOwner(port); ok {
+		return portInUseError(port, pid)
+	}
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return fmt.Errorf("%w: port %d: %v", ErrPortInUse, port, err)
+	}
+	listener.Close()
+	return nil
+}
+
+// portInUseError describes the process listening on port
+func portInUseError(port, pid int) error {
+	if processes, err := listProcesses(); err == nil {
+		for _, process := range processes {
+			if int(process.ProcessID) == pid {
+				return fmt.Errorf("%w: port %d is held by %s (PID %d)", ErrPortInUse, port, windows.UTF16ToString(process.ExeFile[:]), pid)
+			}
+		}
+	}
+	return fmt.Errorf("%w: port %d is held by PID %d", ErrPortInUse, port, pid)
+}
+
+// listeningPortOwner returns the PID of a process listening on TCP port over IPv4 or IPv6
+func listeningPortOwner(port int) (int, bool) {
+	for _, layout := range tcpTableLayouts {
+		table, err := extendedTCPTable(layout.family)
+		if err != nil || len(table) < 4 {
+			continue
+		}
+
+		count := int(binary.LittleEndian.Uint32(table))
+		for i := 0; i < count; i++ {
+			row := table[4+i*layout.rowSize:]
+			if len(row) < layout.rowSize {
+				break
+			}
+			// The port is stored in network byte order in the low 16 bits
+			rawPort := binary.LittleEndian.Uint32(row[layout.portOffset:])
+			if int(rawPort&0xff)<<8|int(rawPort>>8&0xff) == port {
+				return int(binary.LittleEndian.Uint32(row[layout.pidOffset:])), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// extendedTCPTable returns the raw listener table of an address family
+func extendedTCPTable(family uint32) ([]byte, error) {
+	var size uint32
+	procGetExtendedTcpTable.Call(0, uintptr(unsafe.Pointer(&size)), 0, uintptr(family), tcpTableOwnerPIDListener, 0)
+
+	// The table can grow between the size query and the read, so retry a few times
+	for attempt := 0; attempt < 3; attempt++ {
+		if size == 0 {
+			return nil, nil
+		}
+		table := make([]byte, size)
+		ret, _, _ := procGetExtendedTcpTable.Call(uintptr(unsafe.Pointer(&table[0])), uintptr(unsafe.Pointer(&size)), 0, uintptr(family), tcpTableOwnerPIDListener, 0)
+		if ret == 0 {
+			return table[:size], nil
+		}
+		if windows.Errno(ret) != windows.ERROR_INSUFFICIENT_BUFFER {
+			return nil, fmt.Errorf("failed to read TCP table: %v", windows.Errno(ret))
+		}
+	}
+	return nil, fmt.Errorf("failed to read TCP table: it keeps growing")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ServicePreview is what CreateService would register for a configuration
+type ServicePreview struct {
+	ServiceName    string            `json:"serviceName"`
+	DisplayName    string            `json:"displayName"`
+	ImagePath      string            `json:"imagePath"`
+	WorkingDir     string            `json:"workingDir"`
+	LogPath        string            `json:"logPath"`
+	ErrorLogPath   string            `json:"errorLogPath,omitempty"`
+	RegistryValues map[string]string `json:"registryValues"` // values written under the Parameters key
+}
+
+// PreviewService computes the name, wrapper ImagePath, paths and registry values CreateService
+// would use for config, without touching SCM, the registry or the file system.
+// The generated name contains a timestamp, so the real service may get a later one.
+func (wsm *WindowsServiceManager) PreviewService(config ServiceConfig) (*ServicePreview, error) {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	preview, _, err := wsm.planService(config)
+	return preview, err
+}
+
+// planService validates config and resolves everything CreateService needs before it registers
+// the service. It returns the preview and config with its working directory and log path resolved.
+// The caller holds the lock.
+func (wsm *WindowsServiceManager) planService(config ServiceConfig) (*ServicePreview, ServiceConfig, error) {
+	if err := normalizeArgs(&config); err != nil {
+		return nil, ServiceConfig{}, err
+	}
+
+	if errs := wsm.validateServiceConfig(config); len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, e := range errs {
+			messages[i] = e.Error()
+		}
+		return nil, ServiceConfig{}, fmt.Errorf("invalid service configuration: %s", strings.Join(messages, "; "))
+	}
+
+	serviceName := wsm.generateServiceName(config.Name)
+	if config.ServiceName != "" {
+		if wsm.serviceNameTaken(config.ServiceName) {
+			fmt.Printf("Warning: service name %s is taken, using %s\n", config.ServiceName, serviceName)
+		} else {
+			serviceName = config.ServiceName
+		}
+	}
+
+	if _, exists := wsm.services[serviceName]; exists {
+		return nil, ServiceConfig{}, fmt.Errorf("service name already exists: %s", serviceName)
+	}
+
+	if err := validateLoadOrderGroup(config.LoadOrderGroup); err != nil {
+		return nil, ServiceConfig{}, err
+	}
+
+	if err := validateIdleSettings(config.IdleTimeout, config.IdleCriterion); err != nil {
+		return nil, ServiceConfig{}, err
+	}
+
+	if err := validateServiceAccount(config.Account, config.Password); err != nil {
+		return nil, ServiceConfig{}, err
+	}
+
+	if config.LogMaxSizeMB < 0 || (config.LogMaxBackups != nil && *config.LogMaxBackups < 0) {
+		return nil, ServiceConfig{}, fmt.Errorf("log rotation limits must not be negative")
+	}
+
+	if err := validateLogEncoding(config.LogEncoding); err != nil {
+		return nil, ServiceConfig{}, err
+	}
+
+	if config.ListenPort < 0 || config.ListenPort > 65535 {
+		return nil, ServiceConfig{}, fmt.Errorf("listen port must be between 1 and 65535")
+	}
+
+	if config.StopTimeoutSec < 0 || config.StartTimeoutSec < 0 {
+		return nil, ServiceConfig{}, fmt.Errorf("start and stop timeouts must not be negative")
+	}
+
+	if config.MaxRestarts < 0 {
+		return nil, ServiceConfig{}, fmt.Errorf("max restarts must not be negative")
+	}
+
+	for _, extra := range config.ExtraCommands {
+		if _, err := os.Stat(expandServicePath(extra.ExePath)); os.IsNotExist(err) {
+			return nil, ServiceConfig{}, fmt.Errorf("executable does not exist: %s", extra.ExePath)
+		}
+	}
+
+	for key := range config.Env {
+		if key == "" || strings.Contains(key, "=") {
+			return nil, ServiceConfig{}, fmt.Errorf("invalid environment variable name: %q", key)
+		}
+	}
+
+	workingDir := config.WorkingDir
+	if workingDir == "" {
+		workingDir = filepath.Dir(config.ExePath)
+	}
+
+	logPath := wsm.defaultLogPath(serviceName)
+	if config.LogPath != "" {
+		resolved, err := resolvePath(config.LogPath)
+		if err != nil {
+			return nil, ServiceConfig{}, err
+		}
+		logPath = resolved
+	}
+
+	var errorLogPath string
+	if config.ErrorLogPath != "" {
+		resolved, err := resolvePath(config.ErrorLogPath)
+		if err != nil {
+			return nil, ServiceConfig{}, err
+		}
+		errorLogPath = resolved
+	}
+
+	imagePath, err := wrapperImagePath(serviceName)
+	if err != nil {
+		return nil, ServiceConfig{}, err
+	}
+
+	resolved := config
+	resolved.WorkingDir = workingDir
+	resolved.LogPath = logPath
+	resolved.ErrorLogPath = errorLogPath
+
+	values, _, err := serviceParameterValues(resolved)
+	if err != nil {
+		return nil, ServiceConfig{}, err
+	}
+	registryValues := make(map[string]string, len(values))
+	for _, value := range values {
+		registryValues[value.Name] = value.Value
+	}
+
+	return &ServicePreview{
+		ServiceName:    serviceName,
+		DisplayName:    config.Name,
+		ImagePath:      imagePath,
+		WorkingDir:     workingDir,
+		LogPath:        logPath,
+		ErrorLogPath:   errorLogPath,
+		RegistryValues: registryValues,
+	}, resolved, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestPlanServiceConcurrentSameDisplayName(t *testing.T) {
+	wsm := newTestManager(t, newFakeConnector())
+	exePath, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	workingDir := t.TempDir()
+
+	const creates = 50
+	names := make(chan string, creates)
+	errs := make(chan error, creates)
+
+	var wg sync.WaitGroup
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Mirrors createService: plan and register under one lock
+			wsm.mutex.Lock()
+			defer wsm.mutex.Unlock()
+
+			config := ServiceConfig{Name: "My App", ExePath: exePath, WorkingDir: workingDir, LogPath: filepath.Join(workingDir, "app.log")}
+			plan, _, err := wsm.planService(config)
+			if err != nil {
+				errs <- err
+				return
+			}
+			wsm.services[plan.ServiceName] = &Service{ID: plan.ServiceName, Name: config.Name}
+			names <- plan.ServiceName
+		}()
+	}
+	wg.Wait()
+	close(names)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("planService: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for name := range names {
+		if seen[name] {
+			t.Errorf("service name %s was generated twice", name)
+		}
+		seen[name] = true
+	}
+	if len(seen) != creates {
+		t.Errorf("got %d distinct service names, want %d", len(seen), creates)
+	}
+}
+
+func TestValidateServiceConfigReportsDuplicateDisplayName(t *testing.T) {
+	wsm := newTestManager(t, newFakeConnector())
+	addTestService(wsm, "WSM_existing", "stopped").Name = "My App"
+	exePath, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+
+	errs := wsm.ValidateServiceConfig(ServiceConfig{Name: "my app", ExePath: exePath})
+	if len(errs) == 0 || errs[0].Field != "name" {
+		t.Errorf("ValidateServiceConfig = %v, want a duplicate name error", errs)
+	}
+}
@@ -0,0 +1,515 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+)
+
+// OrphanInfo describes a service-wrapper process that no longer matches its service
+type OrphanInfo struct {
+	PID         int    `json:"pid"`
+	ServiceName string `json:"serviceName"`
+	CommandLine string `json:"commandLine"`
+	Managed     bool   `json:"managed"`
+	Reason      string `json:"reason"`
+}
+
+// ResourceUsage is a snapshot of the resources used by a service's target and the processes it started
+type ResourceUsage struct {
+	PID          int       `json:"pid"`          // the target, or the service process if it has no children
+	MemoryBytes  uint64    `json:"memoryBytes"`  // working set, summed over the measured processes
+	CPUPercent   float64   `json:"cpuPercent"`   // share of total CPU capacity since the previous sample
+	ProcessCount int       `json:"processCount"` // processes measured
+	SampledAt    time.Time `json:"sampledAt"`
+}
+
+// cpuSample is the CPU time of a process at a point in time
+type cpuSample struct {
+	serviceID string
+	cpuTime   int64 // 100ns ticks
+	at        time.Time
+}
+
+// cpuSampleMaxAge is how long the sample of a process is kept without being refreshed
+const cpuSampleMaxAge = 10 * time.Minute
+
+var procGetProcessMemoryInfo = modkernel32.NewProc("K32GetProcessMemoryInfo")
+
+// processMemoryCounters mirrors the Win32 PROCESS_MEMORY_COUNTERS structure
+type processMemoryCounters struct {
+	cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// processWorkingSet returns the working set size of a process in bytes
+func processWorkingSet(pid int) (uint64, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open process %d: %v", pid, err)
+	}
+	defer windows.CloseHandle(handle)
+
+	var counters processMemoryCounters
+	counters.cb = uint32(unsafe.Sizeof(counters))
+	ret, _, err := procGetProcessMemoryInfo.Call(uintptr(handle), uintptr(unsafe.Pointer(&counters)), uintptr(counters.cb))
+	if ret == 0 {
+		return 0, fmt.Errorf("failed to get memory info for %d: %v", pid, err)
+	}
+
+	return uint64(counters.WorkingSetSize), nil
+}
+
+// GetServiceResourceUsage samples memory and CPU usage of a running service.
+// For services run by the wrapper the target and its descendants are measured, not the wrapper itself;
+// adopted services are measured with all their descendants. CPU usage is averaged since the previous
+// sample of each process, or since the process started on its first sample.
+func (wsm *WindowsServiceManager) GetServiceResourceUsage(serviceID string) (*ResourceUsage, error) {
+	wsm.mutex.RLock()
+	service, exists := wsm.services[serviceID]
+	var pid int
+	var adopted bool
+	if exists {
+		pid = service.PID
+		adopted = service.Adopted
+	}
+	wsm.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if pid == 0 {
+		return nil, ErrServiceAlreadyStopped
+	}
+
+	tree, err := processTree(pid)
+	if err != nil {
+		return nil, err
+	}
+	measured := tree
+	if !adopted && len(tree) > 1 {
+		measured = tree[1:]
+	}
+	now := time.Now()
+
+	usage := &ResourceUsage{
+		PID:       measured[0].PID,
+		SampledAt: now,
+	}
+	live := make(map[int]bool, len(measured))
+	for _, process := range measured {
+		cpuTime, err := processCPUTime(process.PID)
+		if err != nil {
+			// The process exited after the snapshot was taken
+			continue
+		}
+		live[process.PID] = true
+		usage.MemoryBytes += process.MemoryBytes
+		usage.CPUPercent += wsm.cpuPercent(serviceID, process.PID, cpuTime, now)
+		usage.ProcessCount++
+	}
+	wsm.pruneCPUSamples(serviceID, live, now)
+
+	return usage, nil
+}
+
+// cpuPercent records the CPU time of a process and returns its share of total CPU capacity
+// since its previous sample, or since it started
+func (wsm *WindowsServiceManager) cpuPercent(serviceID string, pid int, cpuTime int64, now time.Time) float64 {
+	startedAt, startErr := processCreationTime(pid)
+
+	wsm.cpuSamplesMutex.Lock()
+	previous, sampled := wsm.cpuSamples[pid]
+	wsm.cpuSamples[pid] = cpuSample{serviceID: serviceID, cpuTime: cpuTime, at: now}
+	wsm.cpuSamplesMutex.Unlock()
+
+	// A sample taken before the process started belongs to an earlier process with the same PID
+	if !sampled || (startErr == nil && previous.at.Before(startedAt)) {
+		previous = cpuSample{at: now}
+		if startErr == nil {
+			previous.at = startedAt
+		}
+	}
+
+	elapsed := now.Sub(previous.at)
+	if elapsed <= 0 || cpuTime < previous.cpuTime {
+		return 0
+	}
+	busy := time.Duration(cpuTime-previous.cpuTime) * 100 // ticks to nanoseconds
+	return float64(busy) / float64(elapsed) / float64(runtime.NumCPU()) * 100
+}
+
+// pruneCPUSamples drops the samples of processes of serviceID that are no longer live,
+// and samples of any service that were not refreshed for cpuSampleMaxAge
+func (wsm *WindowsServiceManager) pruneCPUSamples(serviceID string, live map[int]bool, now time.Time) {
+	wsm.cpuSamplesMutex.Lock()
+	defer wsm.cpuSamplesMutex.Unlock()
+
+	for pid, sample := range wsm.cpuSamples {
+		if (sample.serviceID == serviceID && !live[pid]) || now.Sub(sample.at) > cpuSampleMaxAge {
+			delete(wsm.cpuSamples, pid)
+		}
+	}
+}
+
+// listProcesses returns a snapshot of all running processes
+func listProcesses() ([]windows.ProcessEntry32, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create process snapshot: %v", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+
+	var processes []windows.ProcessEntry32
+	err = windows.Process32First(snapshot, &entry)
+	for err == nil {
+		processes = append(processes, entry)
+		err = windows.Process32Next(snapshot, &entry)
+	}
+	if err != windows.ERROR_NO_MORE_FILES {
+		return nil, fmt.Errorf("failed to enumerate processes: %v", err)
+	}
+
+	return processes, nil
+}
+
+// ProcessInfo describes one process in the process tree of a service
+type ProcessInfo struct {
+	PID         int    `json:"pid"`
+	ParentPID   int    `json:"parentPid"`
+	Name        string `json:"name"`
+	MemoryBytes uint64 `json:"memoryBytes"` // working set, 0 when it cannot be read
+	Depth       int    `json:"depth"`       // 0 for the service process, 1 for its children, ...
+}
+
+// GetServiceProcessTree returns the process of a running service followed by all its descendants,
+// parents before their children
+func (wsm *WindowsServiceManager) GetServiceProcessTree(serviceID string) ([]ProcessInfo, error) {
+	wsm.mutex.RLock()
+	service, exists := wsm.services[serviceID]
+	var pid int
+	if exists {
+		pid = service.PID
+	}
+	wsm.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if pid == 0 {
+		return nil, ErrServiceAlreadyStopped
+	}
+
+	return processTree(pid)
+}
+
+// processTree returns root and its descendants from a process snapshot, parents before children
+func processTree(root int) ([]ProcessInfo, error) {
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]windows.ProcessEntry32)
+	var rootEntry *windows.ProcessEntry32
+	for i := range processes {
+		process := processes[i]
+		if int(process.ProcessID) == root {
+			rootEntry = &processes[i]
+			continue
+		}
+		children[int(process.ParentProcessID)] = append(children[int(process.ParentProcessID)], process)
+	}
+	if rootEntry == nil {
+		return nil, fmt.Errorf("process %d is not running", root)
+	}
+
+	tree := []ProcessInfo{newProcessInfo(*rootEntry, 0)}
+	for i := 0; i < len(tree); i++ {
+		parent := tree[i]
+		parentStart, parentErr := processCreationTime(parent.PID)
+
+		for _, child := range children[parent.PID] {
+			// Parent PIDs are not cleared when a parent exits, so a process started before its
+			// recorded parent belongs to an earlier process that had the same PID
+			if parentErr == nil {
+				if childStart, err := processCreationTime(int(child.ProcessID)); err == nil && childStart.Before(parentStart) {
+					continue
+				}
+			}
+			tree = append(tree, newProcessInfo(child, parent.Depth+1))
+		}
+	}
+
+	return tree, nil
+}
+
+// newProcessInfo describes a snapshot entry
+func newProcessInfo(entry windows.ProcessEntry32, depth int) ProcessInfo {
+	info := ProcessInfo{
+		PID:       int(entry.ProcessID),
+		ParentPID: int(entry.ParentProcessID),
+		Name:      windows.UTF16ToString(entry.ExeFile[:]),
+		Depth:     depth,
+	}
+	if memory, err := processWorkingSet(info.PID); err == nil {
+		info.MemoryBytes = memory
+	}
+	return info
+}
+
+// processCommandLine reads the full command line of another process
+func processCommandLine(pid uint32) (string, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(handle)
+
+	size := uint32(1024)
+	for {
+		buf := make([]byte, size)
+		err := windows.NtQueryInformationProcess(handle, windows.ProcessCommandLineInformation, unsafe.Pointer(&buf[0]), size, &size)
+		if err == nil {
+			return (*windows.NTUnicodeString)(unsafe.Pointer(&buf[0])).String(), nil
+		}
+		if err != windows.STATUS_INFO_LENGTH_MISMATCH || size <= uint32(len(buf)) {
+			return "", err
+		}
+	}
+}
+
+// processCreationTime returns when a process was started
+func processCreationTime(pid int) (time.Time, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to open process %d: %v", pid, err)
+	}
+	defer windows.CloseHandle(handle)
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get process times for %d: %v", pid, err)
+	}
+
+	return time.Unix(0, creation.Nanoseconds()), nil
+}
+
+// processCPUTime returns the total kernel and user CPU time of a process in 100ns units
+func processCPUTime(pid int) (int64, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open process %d: %v", pid, err)
+	}
+	defer windows.CloseHandle(handle)
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return 0, fmt.Errorf("failed to get process times for %d: %v", pid, err)
+	}
+
+	return filetimeTicks(kernel) + filetimeTicks(user), nil
+}
+
+// filetimeTicks converts a FILETIME duration into 100ns ticks
+func filetimeTicks(ft windows.Filetime) int64 {
+	return int64(ft.HighDateTime)<<32 | int64(ft.LowDateTime)
+}
+
+// terminateProcess forcibly ends a process by PID
+func terminateProcess(pid int) error {
+	handle, err := windows.OpenProcess(windows.PROCESS_TERMINATE|windows.SYNCHRONIZE, false, uint32(pid))
+	if err != nil {
+		return fmt.Errorf("failed to open process %d: %v", pid, err)
+	}
+	defer windows.CloseHandle(handle)
+
+	if err := windows.TerminateProcess(handle, 1); err != nil {
+		return fmt.Errorf("failed to terminate process %d: %v", pid, err)
+	}
+
+	windows.WaitForSingleObject(handle, 5000)
+	return nil
+}
+
+var (
+	moduser32 = windows.NewLazySystemDLL("user32.dll")
+
+	procAttachConsole         = modkernel32.NewProc("AttachConsole")
+	procFreeConsole           = modkernel32.NewProc("FreeConsole")
+	procSetConsoleCtrlHandler = modkernel32.NewProc("SetConsoleCtrlHandler")
+	procPostMessageW          = moduser32.NewProc("PostMessageW")
+)
+
+// sendCtrlC delivers Ctrl-C to a console process by briefly attaching to its console.
+// Our own Ctrl-C handling is disabled while attached so the wrapper is not stopped too.
+func sendCtrlC(pid int) error {
+	procFreeConsole.Call()
+	if ret, _, err := procAttachConsole.Call(uintptr(pid)); ret == 0 {
+		return fmt.Errorf("failed to attach to console of process %d: %v", pid, err)
+	}
+	defer procFreeConsole.Call()
+
+	procSetConsoleCtrlHandler.Call(0, 1)
+	defer func() {
+		// Re-enable Ctrl-C only after the event has been dispatched
+		time.Sleep(100 * time.Millisecond)
+		procSetConsoleCtrlHandler.Call(0, 0)
+	}()
+
+	if err := windows.GenerateConsoleCtrlEvent(windows.CTRL_C_EVENT, 0); err != nil {
+		return fmt.Errorf("failed to send Ctrl-C to process %d: %v", pid, err)
+	}
+	return nil
+}
+
+// postCloseToWindows posts WM_CLOSE to every top-level window owned by a process.
+// It returns the number of windows that were asked to close.
+func postCloseToWindows(pid int) int {
+	const WM_CLOSE = 0x0010
+
+	closed := 0
+	callback := windows.NewCallback(func(hwnd windows.HWND, _ uintptr) uintptr {
+		var owner uint32
+		windows.GetWindowThreadProcessId(hwnd, &owner)
+		if int(owner) == pid {
+			procPostMessageW.Call(uintptr(hwnd), WM_CLOSE, 0, 0)
+			closed++
+		}
+		return 1 // continue enumeration
+	})
+	windows.EnumWindows(callback, nil)
+
+	return closed
+}
+
+// wrapperServiceName extracts the service name from a --service-wrapper command line
+func wrapperServiceName(commandLine string) (string, bool) {
+	args, err := windows.DecomposeCommandLine(commandLine)
+	if err != nil {
+		return "", false
+	}
+	for i, arg := range args {
+		if arg == "--service-wrapper" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// FindOrphanedWrappers lists wrapper processes whose service is stopped, deleted, or run by another process
+func (wsm *WindowsServiceManager) FindOrphanedWrappers() ([]OrphanInfo, error) {
+	currentExe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current executable path: %v", err)
+	}
+	exeName := filepath.Base(currentExe)
+
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	orphans := make([]OrphanInfo, 0)
+
+	err = wsm.withSCM(func(scm scmConnection) error {
+		for _, process := range processes {
+			if int(process.ProcessID) == os.Getpid() {
+				continue
+			}
+			if !strings.EqualFold(windows.UTF16ToString(process.ExeFile[:]), exeName) {
+				continue
+			}
+
+			commandLine, err := processCommandLine(process.ProcessID)
+			if err != nil {
+				continue
+			}
+			serviceName, ok := wrapperServiceName(commandLine)
+			if !ok {
+				continue
+			}
+
+			reason := wrapperOrphanReason(scm, serviceName, process.ProcessID)
+			if reason == "" {
+				continue
+			}
+
+			_, managed := wsm.services[serviceName]
+			orphans = append(orphans, OrphanInfo{
+				PID:         int(process.ProcessID),
+				ServiceName: serviceName,
+				CommandLine: commandLine,
+				Managed:     managed,
+				Reason:      reason,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return orphans, nil
+}
+
+// wrapperOrphanReason explains why a wrapper process is orphaned, or returns "" if it is healthy
+func wrapperOrphanReason(scm scmConnection, serviceName string, pid uint32) string {
+	windowsService, err := scm.OpenService(serviceName)
+	if err != nil {
+		return "service no longer exists"
+	}
+	defer windowsService.Close()
+
+	status, err := windowsService.Query()
+	if err != nil {
+		return ""
+	}
+
+	if status.State == svc.Stopped {
+		return "service is stopped"
+	}
+	if status.ProcessId != 0 && status.ProcessId != pid {
+		return fmt.Sprintf("service is running under a different process (PID %d)", status.ProcessId)
+	}
+
+	return ""
+}
+
+// KillOrphanedWrapper terminates a wrapper process previously reported as orphaned
+func (wsm *WindowsServiceManager) KillOrphanedWrapper(pid int) error {
+	orphans, err := wsm.FindOrphanedWrappers()
+	if err != nil {
+		return err
+	}
+
+	for _, orphan := range orphans {
+		if orphan.PID == pid {
+			return terminateProcess(pid)
+		}
+	}
+
+	return fmt.Errorf("process %d is not an orphaned service wrapper", pid)
+}
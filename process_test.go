@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestPruneCPUSamples(t *testing.T) {
+	wsm := newTestManager(t, newFakeConnector())
+	now := time.Now()
+	wsm.cpuSamples = map[int]cpuSample{
+		100: {serviceID: "WSM_a", at: now},
+		101: {serviceID: "WSM_a", at: now},
+		200: {serviceID: "WSM_b", at: now.Add(-time.Minute)},
+		201: {serviceID: "WSM_b", at: now.Add(-cpuSampleMaxAge - time.Minute)},
+	}
+
+	wsm.pruneCPUSamples("WSM_a", map[int]bool{100: true}, now)
+
+	for _, pid := range []int{100, 200} {
+		if _, found := wsm.cpuSamples[pid]; !found {
+			t.Errorf("sample of PID %d was dropped", pid)
+		}
+	}
+	for _, pid := range []int{101, 201} {
+		if _, found := wsm.cpuSamples[pid]; found {
+			t.Errorf("sample of PID %d was kept", pid)
+		}
+	}
+}
+
+func TestGetServiceResourceUsageMeasuresTarget(t *testing.T) {
+	// This test process stands in for the wrapper, a child of it for the target
+	target := exec.Command("cmd.exe", "/c", "ping -n 30 127.0.0.1 >nul")
+	if err := target.Start(); err != nil {
+		t.Fatalf("failed to start target: %v", err)
+	}
+	defer func() {
+		target.Process.Kill()
+		target.Wait()
+	}()
+
+	wsm := newTestManager(t, newFakeConnector())
+	service := addTestService(wsm, "WSM_app", "running")
+	service.PID = os.Getpid()
+
+	usage, err := wsm.GetServiceResourceUsage("WSM_app")
+	if err != nil {
+		t.Fatalf("GetServiceResourceUsage: %v", err)
+	}
+	if usage.PID != target.Process.Pid {
+		t.Errorf("measured PID %d, want the target %d rather than the wrapper %d", usage.PID, target.Process.Pid, os.Getpid())
+	}
+	if usage.ProcessCount == 0 || usage.MemoryBytes == 0 {
+		t.Errorf("usage = %+v, want the target's processes measured", usage)
+	}
+	if _, found := wsm.cpuSamples[os.Getpid()]; found {
+		t.Errorf("the wrapper process was sampled")
+	}
+
+	service.Adopted = true
+	usage, err = wsm.GetServiceResourceUsage("WSM_app")
+	if err != nil {
+		t.Fatalf("GetServiceResourceUsage of an adopted service: %v", err)
+	}
+	if usage.PID != os.Getpid() {
+		t.Errorf("adopted service measured from PID %d, want the service process %d", usage.PID, os.Getpid())
+	}
+}
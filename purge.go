@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// purgeableLogExtensions are the log file types DeleteServiceAndData will remove
+var purgeableLogExtensions = map[string]bool{
+	".log": true,
+	".txt": true,
+}
+
+// PurgeReport lists what DeleteServiceAndData cleaned up besides the service itself
+type PurgeReport struct {
+	ServiceID    string   `json:"serviceId"`
+	RegistryKeys []string `json:"registryKeys"` // registry keys removed
+	Files        []string `json:"files"`        // log files removed
+	Skipped      []string `json:"skipped"`      // leftovers that were kept, with the reason
+}
+
+// DeleteServiceAndData deletes a service like DeleteService, then removes its registry key
+// if SCM left it behind and its log files with the rotated backups
+func (wsm *WindowsServiceManager) DeleteServiceAndData(serviceID string, force bool, token string) (*PurgeReport, error) {
+	wsm.mutex.RLock()
+	service, exists := wsm.services[serviceID]
+	var logPath, errorLogPath string
+	if exists {
+		logPath = service.LogPath
+		errorLogPath = service.ErrorLogPath
+	}
+	wsm.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	if err := wsm.DeleteService(serviceID, force, token); err != nil {
+		return nil, err
+	}
+
+	report := &PurgeReport{
+		ServiceID:    serviceID,
+		RegistryKeys: []string{},
+		Files:        []string{},
+		Skipped:      []string{},
+	}
+
+	wsm.purgeServiceKey(serviceID, report)
+	for _, path := range []string{logPath, errorLogPath} {
+		if path != "" {
+			wsm.purgeServiceLogs(path, report)
+		}
+	}
+
+	return report, nil
+}
+
+// purgeServiceKey removes the registry key of a deleted service if it is still there.
+// The key is only touched once SCM no longer knows the service.
+func (wsm *WindowsServiceManager) purgeServiceKey(serviceName string, report *PurgeReport) {
+	keyPath := `SYSTEM\CurrentControlSet\Services\` + serviceName
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		// SCM removed it together with the service
+		return
+	}
+	subKeys, _ := key.ReadSubKeyNames(0)
+	key.Close()
+
+	err = wsm.withSCM(func(scm scmConnection) error {
+		windowsService, err := scm.OpenService(serviceName)
+		if err == nil {
+			windowsService.Close()
+			return fmt.Errorf("service is still registered")
+		}
+		if !errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("registry key %s: %v", keyPath, err))
+		return
+	}
+
+	for _, subKey := range subKeys {
+		if err := registry.DeleteKey(registry.LOCAL_MACHINE, keyPath+`\`+subKey); err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("registry key %s\\%s: %v", keyPath, subKey, err))
+			return
+		}
+		report.RegistryKeys = append(report.RegistryKeys, keyPath+`\`+subKey)
+	}
+
+	if err := registry.DeleteKey(registry.LOCAL_MACHINE, keyPath); err != nil {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("registry key %s: %v", keyPath, err))
+		return
+	}
+	report.RegistryKeys = append(report.RegistryKeys, keyPath)
+}
+
+// purgeServiceLogs removes a deleted service's log file and its rotated backups.
+// Only the configured log file and its numbered backups are removed, never a directory,
+// a file of another type or a log still used by another service.
+func (wsm *WindowsServiceManager) purgeServiceLogs(logPath string, report *PurgeReport) {
+	logPath = filepath.Clean(logPath)
+
+	if !filepath.IsAbs(logPath) {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("log file %s: path is not absolute", logPath))
+		return
+	}
+
+	ext := filepath.Ext(logPath)
+	if !purgeableLogExtensions[strings.ToLower(ext)] {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("log file %s: only .log and .txt files are removed", logPath))
+		return
+	}
+
+	wsm.mutex.RLock()
+	for _, other := range wsm.services {
+		for _, path := range []string{other.LogPath, other.ErrorLogPath} {
+			if path != "" && strings.EqualFold(filepath.Clean(path), logPath) {
+				wsm.mutex.RUnlock()
+				report.Skipped = append(report.Skipped, fmt.Sprintf("log file %s: still used by service %s", logPath, other.ID))
+				return
+			}
+		}
+	}
+	wsm.mutex.RUnlock()
+
+	candidates := append([]string{logPath}, logBackupFiles(logPath)...)
+	for _, path := range candidates {
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("log file %s: not a regular file", path))
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("log file %s: %v", path, err))
+			continue
+		}
+		report.Files = append(report.Files, path)
+	}
+}
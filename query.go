@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ServiceFilter selects services in QueryServices; empty fields match every service
+type ServiceFilter struct {
+	Text      string `json:"text"`      // case-insensitive substring of the display name or service name
+	Status    string `json:"status"`    // "running", "stopped", "paused", "error", ...
+	Tag       string `json:"tag"`       // case-insensitive tag
+	AutoStart *bool  `json:"autoStart"` // whether the service starts at boot
+}
+
+// matchesStatic reports whether service passes the parts of filter that need no SCM query
+func (filter ServiceFilter) matchesStatic(service *Service) bool {
+	if text := strings.ToLower(strings.TrimSpace(filter.Text)); text != "" {
+		if !strings.Contains(strings.ToLower(service.Name), text) && !strings.Contains(strings.ToLower(service.ID), text) {
+			return false
+		}
+	}
+	if filter.Tag != "" && !hasTag(service, filter.Tag) {
+		return false
+	}
+	if filter.AutoStart != nil && service.AutoStart != *filter.AutoStart {
+		return false
+	}
+	return true
+}
+
+// QueryServices returns copies of the services matching filter with their live status, sorted by name.
+// Only services passing the name, tag and auto-start filters are queried from SCM.
+func (wsm *WindowsServiceManager) QueryServices(filter ServiceFilter) ([]*Service, error) {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	services := make([]*Service, 0)
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		for _, service := range wsm.services {
+			if !filter.matchesStatic(service) {
+				continue
+			}
+
+			snapshot := *service
+			snapshot.Status, snapshot.PID = wsm.getServiceRealTimeStatus(scm, service.ID)
+			if filter.Status != "" && !strings.EqualFold(snapshot.Status, filter.Status) {
+				continue
+			}
+
+			loadLastExit(&snapshot)
+			wsm.refreshUptime(&snapshot)
+			services = append(services, &snapshot)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return strings.ToLower(services[i].Name) < strings.ToLower(services[j].Name)
+	})
+	return services, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// openInExplorer opens Explorer at path. Files are shown selected in their folder.
+func openInExplorer(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("path does not exist: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot access %s: %v", path, err)
+	}
+
+	// Explorer parses its own command line, so /select needs the path quoted as-is
+	args := fmt.Sprintf(`"%s"`, path)
+	if !info.IsDir() {
+		args = fmt.Sprintf(`/select,"%s"`, path)
+	}
+
+	explorer := filepath.Join(os.Getenv("SystemRoot"), "explorer.exe")
+	cmd := exec.Command(explorer)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: fmt.Sprintf(`"%s" %s`, explorer, args),
+	}
+
+	// Explorer reports a non-zero exit code even on success, so it is not waited for
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start Explorer: %v", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// OpenServiceLogFolder shows the log file of a service selected in Explorer
+func (a *App) OpenServiceLogFolder(serviceID string) error {
+	logPath, _, err := a.serviceManager.GetServiceLogPath(serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to get log path: %v", err)
+	}
+
+	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		// No output written yet, so open the folder the log will be created in
+		return openInExplorer(filepath.Dir(logPath))
+	}
+	return openInExplorer(logPath)
+}
+
+// OpenServiceWorkingDir opens the working directory of a service in Explorer
+func (a *App) OpenServiceWorkingDir(serviceID string) error {
+	services, err := a.serviceManager.GetServices()
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		if service.ID != serviceID {
+			continue
+		}
+		return openInExplorer(serviceWorkingDir(service.WorkingDir, service.ExePath))
+	}
+	return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+}
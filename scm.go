@@ -0,0 +1,93 @@
+package main
+
+import (
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// scmConnector opens connections to the Service Control Manager.
+// All SCM work goes through these interfaces so it can run against a fake SCM;
+// mgrConnector is the real one.
+type scmConnector interface {
+	Connect() (scmConnection, error)
+}
+
+// scmConnection is an open connection to the Service Control Manager
+type scmConnection interface {
+	OpenService(name string) (scmService, error)
+	CreateService(name, exePath string, config mgr.Config, args ...string) (scmService, error)
+	ListServices() ([]string, error)
+	Disconnect() error
+}
+
+// scmService is an open handle to a service
+type scmService interface {
+	Name() string
+	Handle() windows.Handle // raw handle for calls mgr does not wrap, 0 on a fake SCM
+	Query() (svc.Status, error)
+	Control(cmd svc.Cmd) (svc.Status, error)
+	Start(args ...string) error
+	Config() (mgr.Config, error)
+	UpdateConfig(config mgr.Config) error
+	ClearDependencies() error // UpdateConfig cannot clear them, it leaves them unchanged when there are none
+	Delete() error
+	RecoveryActions() ([]mgr.RecoveryAction, error)
+	ResetPeriod() (uint32, error)
+	SetRecoveryActions(actions []mgr.RecoveryAction, resetPeriod uint32) error
+	SetRecoveryActionsOnNonCrashFailures(flag bool) error
+	ResetRecoveryActions() error
+	Close() error
+}
+
+// mgrConnector connects to the Service Control Manager of the local machine
+type mgrConnector struct{}
+
+func (mgrConnector) Connect() (scmConnection, error) {
+	scm, err := mgr.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return &mgrConnection{scm}, nil
+}
+
+// mgrConnection is an scmConnection backed by golang.org/x/sys/windows/svc/mgr
+type mgrConnection struct {
+	*mgr.Mgr
+}
+
+func (c *mgrConnection) OpenService(name string) (scmService, error) {
+	service, err := c.Mgr.OpenService(name)
+	if err != nil {
+		return nil, err
+	}
+	return &mgrService{service}, nil
+}
+
+func (c *mgrConnection) CreateService(name, exePath string, config mgr.Config, args ...string) (scmService, error) {
+	service, err := c.Mgr.CreateService(name, exePath, config, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &mgrService{service}, nil
+}
+
+// mgrService is an scmService backed by golang.org/x/sys/windows/svc/mgr
+type mgrService struct {
+	*mgr.Service
+}
+
+func (s *mgrService) Name() string {
+	return s.Service.Name
+}
+
+func (s *mgrService) Handle() windows.Handle {
+	return s.Service.Handle
+}
+
+func (s *mgrService) ClearDependencies() error {
+	// An empty double-NUL terminated list replaces the dependencies, where nil keeps them
+	empty := []uint16{0, 0}
+	return windows.ChangeServiceConfig(s.Service.Handle, windows.SERVICE_NO_CHANGE, windows.SERVICE_NO_CHANGE,
+		windows.SERVICE_NO_CHANGE, nil, nil, nil, &empty[0], nil, nil, nil)
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// fakeConnector hands out connections to a fake SCM holding services in memory.
+// connectErrs are returned by the first calls to Connect, one per call.
+type fakeConnector struct {
+	mutex       sync.Mutex
+	services    map[string]*fakeService
+	connectErrs []error
+	connects    int
+}
+
+func newFakeConnector(services ...*fakeService) *fakeConnector {
+	connector := &fakeConnector{services: make(map[string]*fakeService)}
+	for _, service := range services {
+		connector.services[service.name] = service
+	}
+	return connector
+}
+
+func (fc *fakeConnector) Connect() (scmConnection, error) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	fc.connects++
+	if len(fc.connectErrs) > 0 {
+		err := fc.connectErrs[0]
+		fc.connectErrs = fc.connectErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &fakeConnection{connector: fc}, nil
+}
+
+// fakeConnection is an scmConnection to a fakeConnector
+type fakeConnection struct {
+	connector *fakeConnector
+}
+
+func (c *fakeConnection) OpenService(name string) (scmService, error) {
+	c.connector.mutex.Lock()
+	defer c.connector.mutex.Unlock()
+
+	service, exists := c.connector.services[name]
+	if !exists || service.deleted {
+		return nil, windows.ERROR_SERVICE_DOES_NOT_EXIST
+	}
+	return service, nil
+}
+
+func (c *fakeConnection) CreateService(name, exePath string, config mgr.Config, args ...string) (scmService, error) {
+	c.connector.mutex.Lock()
+	defer c.connector.mutex.Unlock()
+
+	if _, exists := c.connector.services[name]; exists {
+		return nil, windows.ERROR_SERVICE_EXISTS
+	}
+	config.BinaryPathName = windows.ComposeCommandLine(append([]string{exePath}, args...))
+	service := newFakeService(name, svc.Stopped)
+	service.config = config
+	c.connector.services[name] = service
+	return service, nil
+}
+
+func (c *fakeConnection) ListServices() ([]string, error) {
+	c.connector.mutex.Lock()
+	defer c.connector.mutex.Unlock()
+
+	names := make([]string, 0, len(c.connector.services))
+	for name, service := range c.connector.services {
+		if !service.deleted {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (c *fakeConnection) Disconnect() error {
+	return nil
+}
+
+// fakeService is a service whose state moves through scripted transitions.
+// Each Query returns the next status queued by Start or Control, then keeps returning the last one.
+type fakeService struct {
+	mutex   sync.Mutex
+	name    string
+	status  svc.Status
+	pending []svc.Status
+	config  mgr.Config
+	deleted bool
+
+	// startStates and stopStates are the states a service passes through after Start and Stop
+	startStates []svc.State
+	stopStates  []svc.State
+	startErr    error
+	controlErr  error
+
+	starts   int
+	controls []svc.Cmd
+
+	recoveryActions  []mgr.RecoveryAction
+	nonCrashFailures bool
+}
+
+// fakeServicePID is the process ID a running fake service reports
+const fakeServicePID = 4242
+
+func newFakeService(name string, state svc.State) *fakeService {
+	service := &fakeService{
+		name:        name,
+		startStates: []svc.State{svc.StartPending, svc.Running},
+		stopStates:  []svc.State{svc.StopPending, svc.Stopped},
+	}
+	service.status = fakeStatus(state, 0)
+	return service
+}
+
+// fakeStatus builds the status a fake service reports in state
+func fakeStatus(state svc.State, checkPoint uint32) svc.Status {
+	status := svc.Status{State: state, CheckPoint: checkPoint}
+	if state != svc.Stopped {
+		status.ProcessId = fakeServicePID
+		status.Accepts = svc.AcceptStop | svc.AcceptPauseAndContinue
+	}
+	return status
+}
+
+// queue schedules the statuses the next queries return
+func (fs *fakeService) queue(states []svc.State) {
+	fs.pending = fs.pending[:0]
+	for i, state := range states {
+		fs.pending = append(fs.pending, fakeStatus(state, uint32(i+1)))
+	}
+}
+
+func (fs *fakeService) Name() string {
+	return fs.name
+}
+
+func (fs *fakeService) Handle() windows.Handle {
+	return 0
+}
+
+func (fs *fakeService) Query() (svc.Status, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if len(fs.pending) > 0 {
+		fs.status = fs.pending[0]
+		fs.pending = fs.pending[1:]
+	}
+	return fs.status, nil
+}
+
+func (fs *fakeService) Control(cmd svc.Cmd) (svc.Status, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.controls = append(fs.controls, cmd)
+	if fs.controlErr != nil {
+		return fs.status, fs.controlErr
+	}
+
+	switch cmd {
+	case svc.Stop:
+		if fs.status.State == svc.Stopped {
+			return fs.status, windows.ERROR_SERVICE_NOT_ACTIVE
+		}
+		fs.queue(fs.stopStates)
+	case svc.Pause:
+		fs.queue([]svc.State{svc.PausePending, svc.Paused})
+	case svc.Continue:
+		fs.queue([]svc.State{svc.ContinuePending, svc.Running})
+	default:
+		return fs.status, fmt.Errorf("fake service does not handle control %d", cmd)
+	}
+	return fs.status, nil
+}
+
+func (fs *fakeService) Start(args ...string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.starts++
+	if fs.startErr != nil {
+		return fs.startErr
+	}
+	if fs.status.State != svc.Stopped {
+		return windows.ERROR_SERVICE_ALREADY_RUNNING
+	}
+	fs.queue(fs.startStates)
+	return nil
+}
+
+func (fs *fakeService) Config() (mgr.Config, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return fs.config, nil
+}
+
+// UpdateConfig keeps the dependencies when config has none, like ChangeServiceConfig does
+func (fs *fakeService) UpdateConfig(config mgr.Config) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if len(config.Dependencies) == 0 {
+		config.Dependencies = fs.config.Dependencies
+	}
+	fs.config = config
+	return nil
+}
+
+func (fs *fakeService) ClearDependencies() error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.config.Dependencies = nil
+	return nil
+}
+
+func (fs *fakeService) Delete() error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.deleted = true
+	return nil
+}
+
+func (fs *fakeService) RecoveryActions() ([]mgr.RecoveryAction, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return fs.recoveryActions, nil
+}
+
+func (fs *fakeService) ResetPeriod() (uint32, error) {
+	return 0, nil
+}
+
+func (fs *fakeService) SetRecoveryActions(actions []mgr.RecoveryAction, resetPeriod uint32) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.recoveryActions = actions
+	return nil
+}
+
+func (fs *fakeService) SetRecoveryActionsOnNonCrashFailures(flag bool) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.nonCrashFailures = flag
+	return nil
+}
+
+func (fs *fakeService) ResetRecoveryActions() error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.recoveryActions = nil
+	return nil
+}
+
+func (fs *fakeService) Close() error {
+	return nil
+}
+
+// newTestManager returns a manager that talks to connector, keeping its data file and the
+// config dir (audit log, settings) in a temporary directory
+func newTestManager(t *testing.T, connector scmConnector) *WindowsServiceManager {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("AppData", dir)
+
+	return &WindowsServiceManager{
+		services:      make(map[string]*Service),
+		dataFile:      dir + `\data.json`,
+		statusCache:   NewServiceStatusCache(),
+		scm:           connector,
+		confirmations: newConfirmationStore(),
+		namePrefix:    defaultServiceNamePrefix,
+		cpuSamples:    make(map[int]cpuSample),
+	}
+}
+
+// addTestService registers a managed service with the test manager
+func addTestService(wsm *WindowsServiceManager, id, status string) *Service {
+	service := &Service{ID: id, Name: id, Status: status}
+	wsm.services[id] = service
+	return service
+}
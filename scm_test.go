@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestStartServiceRunsThroughStartPending(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "stopped")
+
+	if err := wsm.StartService("WSM_app"); err != nil {
+		t.Fatalf("StartService: %v", err)
+	}
+
+	if fake.starts != 1 {
+		t.Errorf("Start called %d times, want 1", fake.starts)
+	}
+	if service.Status != "running" || service.PID != fakeServicePID {
+		t.Errorf("service is %s with PID %d, want running with PID %d", service.Status, service.PID, fakeServicePID)
+	}
+	if cached, ok := wsm.statusCache.Get("WSM_app"); !ok || cached.Status != "running" {
+		t.Errorf("status cache was not updated to running")
+	}
+}
+
+func TestStartServiceAlreadyRunning(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	addTestService(wsm, "WSM_app", "running")
+
+	err := wsm.StartService("WSM_app")
+	if !errors.Is(err, ErrServiceAlreadyRunning) {
+		t.Fatalf("StartService = %v, want ErrServiceAlreadyRunning", err)
+	}
+	if fake.starts != 0 {
+		t.Errorf("Start called on a running service")
+	}
+}
+
+func TestStartServiceStopsWhileStarting(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	fake.startStates = []svc.State{svc.StartPending, svc.Stopped}
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "stopped")
+
+	err := wsm.StartService("WSM_app")
+	if err == nil || !strings.Contains(err.Error(), "failed to start") {
+		t.Fatalf("StartService = %v, want a start failure", err)
+	}
+	if service.Status != "error" {
+		t.Errorf("service status = %s, want error", service.Status)
+	}
+	if _, ok := wsm.statusCache.Get("WSM_app"); ok {
+		t.Errorf("status cache still holds an entry after a failed start")
+	}
+}
+
+func TestStartServiceLogonFailure(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	fake.startErr = windows.ERROR_SERVICE_LOGON_FAILED
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "stopped")
+	service.Account = `.\svcuser`
+
+	err := wsm.StartService("WSM_app")
+	if err == nil || !strings.Contains(err.Error(), `account .\svcuser could not log on`) {
+		t.Fatalf("StartService = %v, want a logon failure naming the account", err)
+	}
+}
+
+func TestStopServiceRunsThroughStopPending(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "running")
+	service.PID = fakeServicePID
+
+	if err := wsm.stopService("WSM_app"); err != nil {
+		t.Fatalf("stopService: %v", err)
+	}
+
+	if len(fake.controls) != 1 || fake.controls[0] != svc.Stop {
+		t.Errorf("controls sent = %v, want a single stop", fake.controls)
+	}
+	if service.Status != "stopped" || service.PID != 0 {
+		t.Errorf("service is %s with PID %d, want stopped with no PID", service.Status, service.PID)
+	}
+}
+
+func TestStopServiceAlreadyStopped(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "running")
+
+	if err := wsm.stopService("WSM_app"); err != nil {
+		t.Fatalf("stopService: %v", err)
+	}
+	if len(fake.controls) != 0 {
+		t.Errorf("controls sent to a stopped service: %v", fake.controls)
+	}
+	if service.Status != "stopped" {
+		t.Errorf("service status = %s, want stopped", service.Status)
+	}
+}
+
+func TestPauseAndContinueService(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "running")
+
+	if err := wsm.PauseService("WSM_app"); err != nil {
+		t.Fatalf("PauseService: %v", err)
+	}
+	if service.Status != "paused" {
+		t.Errorf("service status = %s after pause, want paused", service.Status)
+	}
+
+	if err := wsm.ContinueService("WSM_app"); err != nil {
+		t.Fatalf("ContinueService: %v", err)
+	}
+	if service.Status != "running" {
+		t.Errorf("service status = %s after continue, want running", service.Status)
+	}
+}
+
+func TestWaitForServiceStateTimesOut(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.StartPending)
+	wsm := newTestManager(t, newFakeConnector(fake))
+
+	err := wsm.waitForServiceState(context.Background(), fake, "WSM_app", svc.Running, time.Second)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("waitForServiceState = %v, want ErrTimeout", err)
+	}
+}
+
+func TestWaitForServiceStateHonoursCancel(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.StartPending)
+	wsm := newTestManager(t, newFakeConnector(fake))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := wsm.waitForServiceState(ctx, fake, "WSM_app", svc.Running, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("waitForServiceState = %v, want context.Canceled", err)
+	}
+}
+
+func TestWaitForServiceStateExtendsTimeoutWithWaitHint(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Stopped)
+	// Reaching Running takes about 1.5s, longer than the timeout, but each poll reports progress
+	fake.pending = []svc.Status{
+		{State: svc.StartPending, CheckPoint: 1, WaitHint: 2000},
+		{State: svc.StartPending, CheckPoint: 2, WaitHint: 2000},
+		{State: svc.StartPending, CheckPoint: 3, WaitHint: 2000},
+		{State: svc.Running, ProcessId: fakeServicePID},
+	}
+	wsm := newTestManager(t, newFakeConnector(fake))
+
+	if err := wsm.waitForServiceState(context.Background(), fake, "WSM_app", svc.Running, time.Second); err != nil {
+		t.Fatalf("waitForServiceState: %v", err)
+	}
+}
+
+func TestConnectSCMRetriesTransientErrors(t *testing.T) {
+	connector := newFakeConnector()
+	connector.connectErrs = []error{windows.RPC_S_SERVER_UNAVAILABLE, windows.RPC_S_SERVER_UNAVAILABLE}
+	wsm := newTestManager(t, connector)
+
+	scm, err := wsm.connectSCM()
+	if err != nil {
+		t.Fatalf("connectSCM: %v", err)
+	}
+	scm.Disconnect()
+
+	if connector.connects != 3 {
+		t.Errorf("Connect called %d times, want 3", connector.connects)
+	}
+}
+
+func TestConnectSCMDoesNotRetryAccessDenied(t *testing.T) {
+	connector := newFakeConnector()
+	connector.connectErrs = []error{windows.ERROR_ACCESS_DENIED}
+	wsm := newTestManager(t, connector)
+
+	err := wsm.withSCM(func(scm scmConnection) error { return nil })
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("withSCM = %v, want ErrAccessDenied", err)
+	}
+	if connector.connects != 1 {
+		t.Errorf("Connect called %d times, want 1", connector.connects)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// protectedSecretPrefix marks values that hold DPAPI ciphertext rather than plaintext
+const protectedSecretPrefix = "dpapi:"
+
+// cryptprotectLocalMachine scopes protection to the machine instead of the calling user,
+// so a secret written by the interactive user can be read by the LocalSystem wrapper
+const cryptprotectLocalMachine = 0x4
+
+// cryptprotectUIForbidden prevents DPAPI from ever showing a prompt (services have no desktop)
+const cryptprotectUIForbidden = 0x1
+
+var (
+	modcrypt32 = windows.NewLazySystemDLL("crypt32.dll")
+
+	procCryptProtectData   = modcrypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = modcrypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = modkernel32.NewProc("LocalFree")
+)
+
+// dataBlob mirrors the Win32 DATA_BLOB structure
+type dataBlob struct {
+	size uint32
+	data *byte
+}
+
+func newDataBlob(b []byte) *dataBlob {
+	if len(b) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{size: uint32(len(b)), data: &b[0]}
+}
+
+// bytes copies the blob contents and releases the buffer allocated by DPAPI
+func (b *dataBlob) bytes() []byte {
+	if b.data == nil {
+		return nil
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(b.data)))
+	return append([]byte(nil), unsafe.Slice(b.data, b.size)...)
+}
+
+// protectSecret encrypts a value with machine-scoped DPAPI and returns it in storable form
+func protectSecret(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	var out dataBlob
+	r1, _, e1 := procCryptProtectData.Call(
+		uintptr(unsafe.Pointer(newDataBlob([]byte(plaintext)))),
+		0, 0, 0, 0,
+		uintptr(cryptprotectLocalMachine|cryptprotectUIForbidden),
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if r1 == 0 {
+		return "", fmt.Errorf("failed to protect secret: %v", e1)
+	}
+
+	return protectedSecretPrefix + base64.StdEncoding.EncodeToString(out.bytes()), nil
+}
+
+// unprotectSecret decrypts a value produced by protectSecret.
+// Values without the DPAPI prefix are treated as legacy plaintext and returned unchanged.
+func unprotectSecret(stored string) (string, error) {
+	if !isProtectedSecret(stored) {
+		return stored, nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, protectedSecretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode protected secret: %v", err)
+	}
+
+	var out dataBlob
+	r1, _, e1 := procCryptUnprotectData.Call(
+		uintptr(unsafe.Pointer(newDataBlob(ciphertext))),
+		0, 0, 0, 0,
+		uintptr(cryptprotectUIForbidden),
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if r1 == 0 {
+		return "", fmt.Errorf("failed to unprotect secret: %v", e1)
+	}
+
+	return string(out.bytes()), nil
+}
+
+// isProtectedSecret reports whether a stored value is DPAPI ciphertext
+func isProtectedSecret(stored string) bool {
+	return strings.HasPrefix(stored, protectedSecretPrefix)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProtectSecretRoundTrip(t *testing.T) {
+	for _, plaintext := range []string{"hunter2", "pässwörd with ünïcode", strings.Repeat("x", 4096)} {
+		protected, err := protectSecret(plaintext)
+		if err != nil {
+			t.Fatalf("protectSecret: %v", err)
+		}
+		if !isProtectedSecret(protected) {
+			t.Errorf("protected value %q lacks the %q prefix", protected, protectedSecretPrefix)
+		}
+		if strings.Contains(protected, plaintext) {
+			t.Errorf("protected value contains the plaintext")
+		}
+
+		got, err := unprotectSecret(protected)
+		if err != nil {
+			t.Fatalf("unprotectSecret: %v", err)
+		}
+		if got != plaintext {
+			t.Errorf("round trip = %q, want %q", got, plaintext)
+		}
+	}
+}
+
+func TestProtectSecretEmpty(t *testing.T) {
+	protected, err := protectSecret("")
+	if err != nil || protected != "" {
+		t.Errorf("protectSecret(\"\") = %q, %v, want an empty value", protected, err)
+	}
+}
+
+func TestUnprotectSecretPassesPlaintextThrough(t *testing.T) {
+	got, err := unprotectSecret(`{"MODE":"prod"}`)
+	if err != nil || got != `{"MODE":"prod"}` {
+		t.Errorf("unprotectSecret(plaintext) = %q, %v, want it unchanged", got, err)
+	}
+}
+
+func TestUnprotectSecretRejectsCorruptCiphertext(t *testing.T) {
+	if _, err := unprotectSecret(protectedSecretPrefix + "not base64!"); err == nil {
+		t.Errorf("unprotectSecret accepted invalid base64")
+	}
+	if _, err := unprotectSecret(protectedSecretPrefix + "AAAA"); err == nil {
+		t.Errorf("unprotectSecret accepted data that is not DPAPI ciphertext")
+	}
+}
+
+func TestServiceParameterValuesProtectsEnv(t *testing.T) {
+	config := ServiceConfig{ExePath: `C:\app\app.exe`, Env: map[string]string{"API_KEY": "s3cret"}}
+
+	values, _, err := serviceParameterValues(config)
+	if err != nil {
+		t.Fatalf("serviceParameterValues: %v", err)
+	}
+
+	for _, value := range values {
+		if value.Name != "Env" {
+			continue
+		}
+		if strings.Contains(value.Value, "s3cret") {
+			t.Fatalf("Env is stored in plaintext: %s", value.Value)
+		}
+		plaintext, err := unprotectSecret(value.Value)
+		if err != nil {
+			t.Fatalf("unprotectSecret: %v", err)
+		}
+		var env map[string]string
+		if err := json.Unmarshal([]byte(plaintext), &env); err != nil || env["API_KEY"] != "s3cret" {
+			t.Errorf("Env decrypts to %q, want the original variables", plaintext)
+		}
+		return
+	}
+	t.Errorf("serviceParameterValues did not store Env")
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultServiceNamePrefix is prepended to generated service names unless configured otherwise
+const defaultServiceNamePrefix = "WSM_"
+
+// AppSettings holds application-wide preferences
+type AppSettings struct {
+	ServiceNamePrefix string `json:"serviceNamePrefix"`
+	NotifyOnCrash     bool   `json:"notifyOnCrash"` // show a toast when a service stops unexpectedly
+	LogDir            string `json:"logDir"`        // folder for new service logs, empty for the default
+	MaxPathLength     int    `json:"maxPathLength"` // longest PATH written, 0 for the default
+
+	// DisableGenericLogEvents stops emitting service-log-line for every tailed service;
+	// lines are then only sent on the per-service service-log-line:<serviceId> events
+	DisableGenericLogEvents bool `json:"disableGenericLogEvents"`
+}
+
+// defaultSettings returns the settings used when nothing has been saved yet
+func defaultSettings() AppSettings {
+	return AppSettings{
+		ServiceNamePrefix: defaultServiceNamePrefix,
+	}
+}
+
+// currentSettings returns a copy of the settings in effect
+func (a *App) currentSettings() AppSettings {
+	a.settingsMutex.Lock()
+	defer a.settingsMutex.Unlock()
+
+	return a.settings
+}
+
+// updateSettings applies change to a copy of the settings, saves it and puts it into effect.
+// The settings are read by log tailers while the UI changes them, so both go through settingsMutex.
+func (a *App) updateSettings(change func(settings *AppSettings)) error {
+	a.settingsMutex.Lock()
+	defer a.settingsMutex.Unlock()
+
+	settings := a.settings
+	change(&settings)
+	if err := saveSettings(settings); err != nil {
+		return fmt.Errorf("failed to save settings: %v", err)
+	}
+
+	a.settings = settings
+	return nil
+}
+
+// getSettingsPath returns the path to the settings file
+func getSettingsPath() (string, error) {
+	return configFilePath("settings.json")
+}
+
+// loadSettings reads saved settings, falling back to defaults for anything missing
+func loadSettings() AppSettings {
+	settings := defaultSettings()
+
+	path, err := getSettingsPath()
+	if err != nil {
+		return settings
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return settings
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return defaultSettings()
+	}
+	if err := validateServiceNamePrefix(settings.ServiceNamePrefix); err != nil {
+		settings.ServiceNamePrefix = defaultServiceNamePrefix
+	}
+
+	return settings
+}
+
+// saveSettings writes settings to disk
+func saveSettings(settings AppSettings) error {
+	path, err := getSettingsPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// validateServiceNamePrefix checks that a prefix only contains characters allowed in service names
+func validateServiceNamePrefix(prefix string) error {
+	if len(prefix) > 32 {
+		return fmt.Errorf("service name prefix must be at most 32 characters")
+	}
+	for _, r := range prefix {
+		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-') {
+			return fmt.Errorf("service name prefix may only contain letters, digits, '_' and '-'")
+		}
+	}
+	return nil
+}
+
+// GetServiceNamePrefix returns the prefix used for new service names
+func (a *App) GetServiceNamePrefix() string {
+	return a.currentSettings().ServiceNamePrefix
+}
+
+// SetServiceNamePrefix changes the prefix used for new service names.
+// Existing services keep their names; only services created afterwards use the new prefix.
+func (a *App) SetServiceNamePrefix(prefix string) error {
+	if err := validateServiceNamePrefix(prefix); err != nil {
+		return err
+	}
+
+	err := a.updateSettings(func(settings *AppSettings) {
+		settings.ServiceNamePrefix = prefix
+	})
+	if err != nil {
+		return err
+	}
+	a.serviceManager.SetNamePrefix(prefix)
+	return nil
+}
+
+// GetNotifyOnCrash reports whether a toast is shown when a service stops unexpectedly
+func (a *App) GetNotifyOnCrash() bool {
+	return a.currentSettings().NotifyOnCrash
+}
+
+// SetNotifyOnCrash turns crash toasts on or off
+func (a *App) SetNotifyOnCrash(enabled bool) error {
+	err := a.updateSettings(func(settings *AppSettings) {
+		settings.NotifyOnCrash = enabled
+	})
+	if err != nil {
+		return err
+	}
+	a.serviceManager.SetNotifyOnCrash(enabled)
+	return nil
+}
+
+// GetLogDir returns the directory new services log to
+func (a *App) GetLogDir() string {
+	if dir := a.currentSettings().LogDir; dir != "" {
+		return dir
+	}
+	return defaultLogDir()
+}
+
+// SetLogDir changes the directory new services log to, each in a folder of its own.
+// Existing services keep their log paths. An empty dir goes back to the default location.
+func (a *App) SetLogDir(dir string) error {
+	if dir != "" {
+		resolved, err := resolvePath(dir)
+		if err != nil {
+			return err
+		}
+		if err := ensureWritableDir(resolved); err != nil {
+			return err
+		}
+		dir = resolved
+	}
+
+	err := a.updateSettings(func(settings *AppSettings) {
+		settings.LogDir = dir
+	})
+	if err != nil {
+		return err
+	}
+	a.serviceManager.SetLogDir(dir)
+	return nil
+}
+
+// GetMaxPathLength returns the longest PATH, in characters, the environment editor writes
+func (a *App) GetMaxPathLength() int {
+	return a.environmentManager.maxPathLength
+}
+
+// SetMaxPathLength changes the longest PATH the environment editor writes; 0 restores the default
+func (a *App) SetMaxPathLength(length int) error {
+	if length < 0 {
+		return fmt.Errorf("maximum PATH length must not be negative")
+	}
+
+	err := a.updateSettings(func(settings *AppSettings) {
+		settings.MaxPathLength = length
+	})
+	if err != nil {
+		return err
+	}
+	return a.environmentManager.SetMaxPathLength(length)
+}
+
+// GetGenericLogEvents reports whether tailed log lines are also sent on the shared service-log-line event
+func (a *App) GetGenericLogEvents() bool {
+	return !a.currentSettings().DisableGenericLogEvents
+}
+
+// SetGenericLogEvents turns the shared service-log-line event on or off.
+// The per-service service-log-line:<serviceId> events are always sent.
+func (a *App) SetGenericLogEvents(enabled bool) error {
+	return a.updateSettings(func(settings *AppSettings) {
+		settings.DisableGenericLogEvents = !enabled
+	})
+}
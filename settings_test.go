@@ -0,0 +1,37 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestGenericLogEventsToggledWhileTailing changes the setting while other goroutines read it the way
+// tailers do for every line. It is meant to be run with -race.
+func TestGenericLogEventsToggledWhileTailing(t *testing.T) {
+	app := newTestApp(t)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				_ = app.currentSettings().DisableGenericLogEvents
+			}
+		}()
+	}
+
+	for i := 0; i <= 20; i++ {
+		if err := app.SetGenericLogEvents(i%2 == 0); err != nil {
+			t.Fatalf("SetGenericLogEvents: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if !app.GetGenericLogEvents() {
+		t.Errorf("generic log events are off, want the last value set (on)")
+	}
+	if loaded := loadSettings(); loaded.DisableGenericLogEvents {
+		t.Errorf("saved settings do not hold the last value set")
+	}
+}
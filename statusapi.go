@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// statusServerHost keeps the status API reachable from this machine only
+const statusServerHost = "127.0.0.1"
+
+// statusTokenHeader carries the token that authorizes status API requests
+const statusTokenHeader = "X-WSM-Token"
+
+// StartStatusServer starts a read-only HTTP API on 127.0.0.1:port serving GET /services and
+// GET /services/{id}. Requests must send the token from GetStatusServerToken in the X-WSM-Token header.
+func (a *App) StartStatusServer(port int) error {
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid port: %d", port)
+	}
+
+	a.statusServerMutex.Lock()
+	defer a.statusServerMutex.Unlock()
+
+	if a.statusServer != nil {
+		return fmt.Errorf("status server is already running on %s", a.statusServer.Addr)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Errorf("failed to generate status token: %v", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /services", a.handleStatusServices)
+	mux.HandleFunc("GET /services/{id}", a.handleStatusService)
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort(statusServerHost, fmt.Sprint(port)),
+		Handler:           requireStatusToken(token, mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", server.Addr, err)
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Warning: status server stopped: %v\n", err)
+		}
+	}()
+
+	a.statusServer = server
+	a.statusToken = token
+	return nil
+}
+
+// StopStatusServer stops the status API if it is running
+func (a *App) StopStatusServer() error {
+	a.statusServerMutex.Lock()
+	defer a.statusServerMutex.Unlock()
+
+	if a.statusServer == nil {
+		return nil
+	}
+
+	err := a.statusServer.Close()
+	a.statusServer = nil
+	a.statusToken = ""
+	if err != nil {
+		return fmt.Errorf("failed to stop status server: %v", err)
+	}
+	return nil
+}
+
+// GetStatusServerToken returns the token clients must send, or an empty string if the server is stopped
+func (a *App) GetStatusServerToken() string {
+	a.statusServerMutex.Lock()
+	defer a.statusServerMutex.Unlock()
+
+	return a.statusToken
+}
+
+// requireStatusToken rejects requests that do not carry token
+func requireStatusToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent := r.Header.Get(statusTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStatusServices serves the list of all managed services.
+// Status requests only read a snapshot; unlike GetServices they never update or save the stored services.
+func (a *App) handleStatusServices(w http.ResponseWriter, r *http.Request) {
+	services, err := a.serviceManager.QueryServices(ServiceFilter{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeStatusJSON(w, services)
+}
+
+// handleStatusService serves a single managed service
+func (a *App) handleStatusService(w http.ResponseWriter, r *http.Request) {
+	services, err := a.serviceManager.QueryServices(ServiceFilter{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id := r.PathValue("id")
+	for _, service := range services {
+		if service.ID == id {
+			writeStatusJSON(w, service)
+			return
+		}
+	}
+	http.Error(w, "service not found", http.StatusNotFound)
+}
+
+// writeStatusJSON writes value as a JSON response
+func writeStatusJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		fmt.Printf("Warning: failed to write status response: %v\n", err)
+	}
+}
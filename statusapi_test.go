@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestStatusHandlersDoNotUpdateServices(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "stopped")
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	service.UpdatedAt = updatedAt
+	app := &App{serviceManager: wsm}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /services", app.handleStatusServices)
+	mux.HandleFunc("GET /services/{id}", app.handleStatusService)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/services", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /services = %d: %s", rec.Code, rec.Body)
+	}
+	var services []Service
+	if err := json.Unmarshal(rec.Body.Bytes(), &services); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(services) != 1 || services[0].Status != "running" {
+		t.Fatalf("GET /services = %+v, want WSM_app running", services)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/services/WSM_app", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /services/WSM_app = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/services/WSM_missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /services/WSM_missing = %d, want 404", rec.Code)
+	}
+
+	if service.Status != "stopped" || !service.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("status requests changed the stored service to %s at %v", service.Status, service.UpdatedAt)
+	}
+	if _, err := os.Stat(wsm.dataFile); !os.IsNotExist(err) {
+		t.Errorf("status requests saved the data file")
+	}
+}
@@ -4,13 +4,30 @@ import (
 	"github.com/getlantern/systray"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"os"
+	"sort"
+	"sync"
 )
 
+// maxTrayServices caps how many services are listed in the tray menu
+const maxTrayServices = 10
+
+// trayServiceSlot is a reusable tray submenu for one service.
+// systray cannot remove items, so a fixed set of slots is created up front and hidden when unused.
+type trayServiceSlot struct {
+	item      *systray.MenuItem
+	toggle    *systray.MenuItem
+	serviceID string
+}
+
 // SystrayManager manages the system tray
 type SystrayManager struct {
 	app      *App
 	trayIcon []byte
 	quitCh   chan struct{}
+
+	slots     []*trayServiceSlot
+	mMore     *systray.MenuItem
+	menuMutex sync.Mutex
 }
 
 // NewSystrayManager creates a new system tray manager
@@ -48,8 +65,18 @@ func (s *SystrayManager) onReady() {
 
 	mShow := systray.AddMenuItem("Show Window", "Show main window")
 	systray.AddSeparator()
+	s.addServiceSlots()
+	systray.AddSeparator()
 	mExit := systray.AddMenuItem("Exit Program", "Exit application")
 
+	s.refreshServiceMenu()
+	runtime.EventsOn(s.app.ctx, "services-updated", func(optionalData ...interface{}) {
+		go s.refreshServiceMenu()
+	})
+	runtime.EventsOn(s.app.ctx, "service-status-changed", func(optionalData ...interface{}) {
+		go s.refreshServiceMenu()
+	})
+
 	go func() {
 		for {
 			select {
@@ -67,6 +94,110 @@ func (s *SystrayManager) onReady() {
 	}()
 }
 
+// addServiceSlots creates the hidden per-service submenus and the "More…" item
+func (s *SystrayManager) addServiceSlots() {
+	s.menuMutex.Lock()
+	defer s.menuMutex.Unlock()
+
+	for i := 0; i < maxTrayServices; i++ {
+		item := systray.AddMenuItem("", "")
+		slot := &trayServiceSlot{
+			item:   item,
+			toggle: item.AddSubMenuItemCheckbox("Running", "Start or stop this service", false),
+		}
+		item.Hide()
+		s.slots = append(s.slots, slot)
+
+		go s.handleSlotClicks(slot)
+	}
+
+	s.mMore = systray.AddMenuItem("More…", "Show all services in the main window")
+	s.mMore.Hide()
+
+	go func() {
+		for {
+			select {
+			case <-s.mMore.ClickedCh:
+				s.app.ShowWindow()
+			case <-s.quitCh:
+				return
+			}
+		}
+	}()
+}
+
+// handleSlotClicks starts or stops the service shown in a slot when its toggle is clicked
+func (s *SystrayManager) handleSlotClicks(slot *trayServiceSlot) {
+	for {
+		select {
+		case <-slot.toggle.ClickedCh:
+			s.menuMutex.Lock()
+			serviceID := slot.serviceID
+			running := slot.toggle.Checked()
+			s.menuMutex.Unlock()
+
+			if serviceID == "" {
+				continue
+			}
+
+			var err error
+			if running {
+				err = s.app.StopService(serviceID)
+			} else {
+				err = s.app.StartService(serviceID)
+			}
+			if err != nil {
+				// Errors (including critical-service confirmations) are handled by the main window
+				s.app.ShowWindow()
+			}
+			s.refreshServiceMenu()
+
+		case <-s.quitCh:
+			return
+		}
+	}
+}
+
+// refreshServiceMenu updates the service slots from the current service list
+func (s *SystrayManager) refreshServiceMenu() {
+	services, err := s.app.serviceManager.GetServices()
+	if err != nil {
+		return
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
+	s.menuMutex.Lock()
+	defer s.menuMutex.Unlock()
+
+	for i, slot := range s.slots {
+		if i >= len(services) {
+			slot.serviceID = ""
+			slot.item.Hide()
+			continue
+		}
+
+		service := services[i]
+		slot.serviceID = service.ID
+		slot.item.SetTitle(service.Name)
+		slot.item.SetTooltip(service.ID + " (" + service.Status + ")")
+		if service.Status == "running" {
+			slot.toggle.Check()
+		} else {
+			slot.toggle.Uncheck()
+		}
+		slot.item.Show()
+	}
+
+	if len(services) > len(s.slots) {
+		s.mMore.Show()
+	} else {
+		s.mMore.Hide()
+	}
+}
+
 // ExitApp exits the application
 func (s *SystrayManager) ExitApp() {
 	select {
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// normalizeTags trims tags and drops empty and duplicate (case-insensitive) ones
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// SetServiceTags replaces the tags of a service
+func (wsm *WindowsServiceManager) SetServiceTags(serviceID string, tags []string) error {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	service, exists := wsm.services[serviceID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+
+	service.Tags = normalizeTags(tags)
+	service.UpdatedAt = time.Now()
+	wsm.saveServices()
+
+	// Emit service list update event
+	wsm.emitServicesUpdated()
+
+	return nil
+}
+
+// GetServicesByTag returns copies of the services carrying tag (case-insensitive), sorted by name
+func (wsm *WindowsServiceManager) GetServicesByTag(tag string) []*Service {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	services := make([]*Service, 0)
+	for _, service := range wsm.services {
+		if hasTag(service, tag) {
+			snapshot := *service
+			services = append(services, &snapshot)
+		}
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return strings.ToLower(services[i].Name) < strings.ToLower(services[j].Name)
+	})
+	return services
+}
+
+// taggedServiceIDs returns the IDs of the services carrying tag
+func (wsm *WindowsServiceManager) taggedServiceIDs(tag string) []string {
+	services := wsm.GetServicesByTag(tag)
+	ids := make([]string, len(services))
+	for i, service := range services {
+		ids[i] = service.ID
+	}
+	return ids
+}
+
+// StartServicesByTag starts every service carrying tag and returns serviceID -> error message (empty on success)
+func (wsm *WindowsServiceManager) StartServicesByTag(tag string) map[string]string {
+	return wsm.StartServices(wsm.taggedServiceIDs(tag))
+}
+
+// StopServicesByTag stops every service carrying tag and returns serviceID -> error message (empty on success)
+func (wsm *WindowsServiceManager) StopServicesByTag(tag string) map[string]string {
+	return wsm.StopServices(wsm.taggedServiceIDs(tag))
+}
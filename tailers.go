@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// getMonitoredConfigPath returns the path to the file listing monitored services
+func getMonitoredConfigPath() (string, error) {
+	return configFilePath("monitored.json")
+}
+
+// monitoredServiceIDs returns the IDs of services with an active tailer; the caller holds logTailersLock
+func (a *App) monitoredServiceIDs() []string {
+	ids := make([]string, 0, len(a.logTailers))
+	for id := range a.logTailers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// GetMonitoredServices returns the IDs of services whose log is being monitored
+func (a *App) GetMonitoredServices() []string {
+	a.logTailersLock.Lock()
+	defer a.logTailersLock.Unlock()
+
+	return a.monitoredServiceIDs()
+}
+
+// saveMonitoredServices persists the monitored services so they are restored on the next start;
+// the caller holds logTailersLock
+func (a *App) saveMonitoredServices() {
+	path, err := getMonitoredConfigPath()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Printf("Warning: failed to save monitored services: %v\n", err)
+		return
+	}
+
+	data, err := json.MarshalIndent(a.monitoredServiceIDs(), "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fmt.Printf("Warning: failed to save monitored services: %v\n", err)
+	}
+}
+
+// restoreMonitoredServices restarts the tailers that were active when the app last ran.
+// Services that no longer exist are dropped from the saved list.
+func (a *App) restoreMonitoredServices() {
+	path, err := getMonitoredConfigPath()
+	if err != nil {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		fmt.Printf("Warning: ignoring invalid monitored services file: %v\n", err)
+		return
+	}
+
+	for _, id := range ids {
+		if _, _, err := a.serviceManager.GetServiceLogPath(id); err != nil {
+			continue
+		}
+		if err := a.startMonitoring(id, 0); err != nil {
+			fmt.Printf("Warning: failed to resume monitoring %s: %v\n", id, err)
+		}
+	}
+
+	a.logTailersLock.Lock()
+	a.saveMonitoredServices()
+	a.logTailersLock.Unlock()
+}
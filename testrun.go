@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// defaultTestRunTimeoutSec is how long TestRunService lets the target run when no timeout is given
+const defaultTestRunTimeoutSec = 10
+
+// maxTestRunTimeoutSec is the longest a test run may take
+const maxTestRunTimeoutSec = 300
+
+// maxTestRunOutput is how much output a test run keeps
+const maxTestRunOutput = 256 * 1024
+
+// TestRunResult is the outcome of TestRunService
+type TestRunResult struct {
+	ExitCode   *int   `json:"exitCode"` // nil when the target was still running at the timeout
+	TimedOut   bool   `json:"timedOut"` // the target ran until the timeout and was killed; usually a good sign for a service
+	Output     string `json:"output"`   // stdout and stderr as they were written
+	Truncated  bool   `json:"truncated"`
+	DurationMs int64  `json:"durationMs"`
+	Hint       string `json:"hint,omitempty"` // explanation of a well-known failure exit code
+}
+
+// exitCodeHints explains exit codes Windows uses when a program cannot even start
+var exitCodeHints = map[uint32]string{
+	0xC0000135: "a DLL the program needs was not found",
+	0xC0000139: "a DLL the program needs is missing a function, it may be the wrong version",
+	0xC000007B: "the program or one of its DLLs is for a different architecture (32/64-bit)",
+	0xC0000142: "a DLL the program needs failed to initialize",
+}
+
+// cappedBuffer keeps the first limit bytes written to it and drops the rest
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (cb *cappedBuffer) Write(p []byte) (int, error) {
+	if room := cb.limit - cb.buf.Len(); room < len(p) {
+		cb.truncated = true
+		if room > 0 {
+			cb.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return cb.buf.Write(p)
+}
+
+// TestRunService runs the target of config directly, outside the service wrapper, so problems such as
+// a missing DLL or bad arguments show up before the service is created. The target runs until it
+// exits or timeoutSec passes, after which it is killed together with any processes it started.
+func (a *App) TestRunService(config ServiceConfig, timeoutSec int) (*TestRunResult, error) {
+	if timeoutSec <= 0 {
+		timeoutSec = defaultTestRunTimeoutSec
+	}
+	if timeoutSec > maxTestRunTimeoutSec {
+		return nil, fmt.Errorf("test run timeout must be at most %d seconds", maxTestRunTimeoutSec)
+	}
+
+	if err := normalizeArgs(&config); err != nil {
+		return nil, err
+	}
+	if err := validateExecutable(config.ExePath); err != nil {
+		return nil, err
+	}
+	if err := validateLogEncoding(config.LogEncoding); err != nil {
+		return nil, err
+	}
+
+	program, args, err := scriptCommand(expandServicePath(config.ExePath), config.ArgsList)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(program, args...)
+	cmd.Dir = serviceWorkingDir(config.WorkingDir, config.ExePath)
+	if _, err := os.Stat(cmd.Dir); err != nil {
+		return nil, fmt.Errorf("working directory is not accessible: %v", err)
+	}
+	if len(config.Env) > 0 {
+		cmd.Env = os.Environ()
+		for key, value := range config.Env {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+
+	output := &cappedBuffer{limit: maxTestRunOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow: true,
+	}
+	// Children that inherited the output pipe must not keep Wait from returning once the target exits
+	cmd.WaitDelay = time.Second
+
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start target process: %v", err)
+	}
+
+	// Closing the job kills whatever the target started, so a test run leaves nothing behind
+	job, err := newKillOnCloseJob(cmd.Process.Pid)
+	if err != nil {
+		log.Printf("Test run children will not be stopped: %v", err)
+	}
+	defer func() {
+		if job != 0 {
+			windows.CloseHandle(job)
+		}
+	}()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	result := &TestRunResult{}
+	select {
+	case err := <-exited:
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+			return nil, fmt.Errorf("failed to wait for target process: %v", err)
+		}
+		exitCode := cmd.ProcessState.ExitCode()
+		result.ExitCode = &exitCode
+		result.Hint = exitCodeHints[uint32(exitCode)]
+	case <-time.After(time.Duration(timeoutSec) * time.Second):
+		result.TimedOut = true
+		if job != 0 {
+			windows.CloseHandle(job)
+			job = 0
+		}
+		cmd.Process.Kill()
+		<-exited
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+
+	decode := newLogLineDecoder(config.LogEncoding)
+	lines := strings.Split(output.buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = decode(strings.TrimRight(line, "\r"))
+	}
+	result.Output = strings.Join(lines, "\n")
+	result.Truncated = output.truncated
+
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/sys/windows/registry"
+)
+
+// themeAuto follows the Windows light/dark app setting
+const themeAuto = "auto"
+
+// themeWatchInterval is how often the OS theme is checked while "auto" is selected
+const themeWatchInterval = 2 * time.Second
+
+// isValidTheme reports whether theme is a supported theme preference
+func isValidTheme(theme string) bool {
+	return theme == "light" || theme == "dark" || theme == themeAuto
+}
+
+// systemTheme returns the app theme selected in the Windows personalization settings
+func systemTheme() string {
+	key, err := registry.OpenKey(registry.CURRENT_USER,
+		`Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, registry.QUERY_VALUE)
+	if err != nil {
+		return "light"
+	}
+	defer key.Close()
+
+	value, _, err := key.GetIntegerValue("AppsUseLightTheme")
+	if err != nil || value != 0 {
+		return "light"
+	}
+	return "dark"
+}
+
+// GetEffectiveTheme returns the theme to display ("light" or "dark"), resolving "auto" to the OS setting
+func (a *App) GetEffectiveTheme() string {
+	theme := a.GetTheme()
+	if theme == themeAuto {
+		return systemTheme()
+	}
+	return theme
+}
+
+// startThemeWatcher emits theme-changed whenever the OS theme flips
+func (a *App) startThemeWatcher() {
+	a.themeWatcherMutex.Lock()
+	defer a.themeWatcherMutex.Unlock()
+
+	if a.themeWatcherCancel != nil || a.ctx == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.themeWatcherCancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(themeWatchInterval)
+		defer ticker.Stop()
+
+		current := systemTheme()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				theme := systemTheme()
+				if theme == current {
+					continue
+				}
+				current = theme
+				runtime.EventsEmit(a.ctx, "theme-changed", theme)
+			}
+		}
+	}()
+}
+
+// stopThemeWatcher stops watching the OS theme
+func (a *App) stopThemeWatcher() {
+	a.themeWatcherMutex.Lock()
+	defer a.themeWatcherMutex.Unlock()
+
+	if a.themeWatcherCancel != nil {
+		a.themeWatcherCancel()
+		a.themeWatcherCancel = nil
+	}
+}
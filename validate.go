@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ValidationError describes a problem with one field of a ServiceConfig
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// runnableExtensions are the file types the wrapper can launch
+var runnableExtensions = map[string]bool{
+	".exe": true,
+	".bat": true,
+	".cmd": true,
+	".ps1": true,
+}
+
+// scriptInterpreters maps script extensions to the interpreter command line that runs them
+var scriptInterpreters = map[string][]string{
+	".bat": {"cmd.exe", "/c"},
+	".cmd": {"cmd.exe", "/c"},
+	".ps1": {"powershell.exe", "-ExecutionPolicy", "Bypass", "-File"},
+}
+
+// scriptCommand returns the program and arguments that run path with args.
+// Scripts are run through their interpreter, which must be on the PATH.
+func scriptCommand(path string, args []string) (string, []string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	interpreter, isScript := scriptInterpreters[ext]
+	if !isScript {
+		return path, args, nil
+	}
+
+	program, err := exec.LookPath(interpreter[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("%s scripts are run with %s, which was not found: %v", ext, interpreter[0], err)
+	}
+
+	scriptArgs := make([]string, 0, len(interpreter)+len(args))
+	scriptArgs = append(scriptArgs, interpreter[1:]...)
+	scriptArgs = append(scriptArgs, path)
+	scriptArgs = append(scriptArgs, args...)
+	return program, scriptArgs, nil
+}
+
+// ValidateServiceConfig checks a service configuration before it is created.
+// It returns an empty list when the configuration is usable.
+// Unlike CreateService it also reports a display name that is already in use, so the UI can ask
+// for a distinct one; CreateService accepts it, since every service gets a unique service name.
+func (wsm *WindowsServiceManager) ValidateServiceConfig(config ServiceConfig) []ValidationError {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	errs := wsm.validateServiceConfig(config)
+	if err := wsm.checkDisplayNameUnused(config.Name); err != nil {
+		errs = append([]ValidationError{*err}, errs...)
+	}
+	return errs
+}
+
+// checkDisplayNameUnused returns an error when a managed service already has the display name name
+func (wsm *WindowsServiceManager) checkDisplayNameUnused(name string) *ValidationError {
+	name = strings.TrimSpace(name)
+	for _, service := range wsm.services {
+		if name != "" && strings.EqualFold(service.Name, name) {
+			return &ValidationError{Field: "name", Message: fmt.Sprintf("a service named %q already exists", service.Name)}
+		}
+	}
+	return nil
+}
+
+// validateServiceConfig holds the checks shared by CreateService and ValidateServiceConfig; the caller holds the lock
+func (wsm *WindowsServiceManager) validateServiceConfig(config ServiceConfig) []ValidationError {
+	errs := make([]ValidationError, 0)
+
+	if strings.TrimSpace(config.Name) == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "name is required"})
+	}
+
+	if config.ServiceName != "" {
+		if err := validateServiceName(config.ServiceName); err != nil {
+			errs = append(errs, ValidationError{Field: "serviceName", Message: err.Error()})
+		}
+	}
+
+	if err := validateExecutable(config.ExePath); err != nil {
+		errs = append(errs, ValidationError{Field: "exePath", Message: err.Error()})
+	}
+
+	if config.WorkingDir != "" {
+		if err := validateWorkingDir(serviceWorkingDir(config.WorkingDir, config.ExePath)); err != nil {
+			errs = append(errs, ValidationError{Field: "workingDir", Message: err.Error()})
+		}
+	}
+
+	if strings.Count(config.Args, `"`)%2 != 0 {
+		errs = append(errs, ValidationError{Field: "args", Message: "arguments contain an unbalanced quote"})
+	}
+
+	if err := validateHealthCheck(config.HealthCheckType, config.HealthCheckTarget, config.HealthCheckIntervalSec); err != nil {
+		errs = append(errs, ValidationError{Field: "healthCheckTarget", Message: err.Error()})
+	}
+
+	return errs
+}
+
+// validateExecutable checks that path exists and is something the wrapper can run
+func validateExecutable(path string) error {
+	if path == "" {
+		return fmt.Errorf("executable is required")
+	}
+	path = expandServicePath(path)
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("executable path must be absolute: %s", path)
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("executable does not exist: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot access executable: %v", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("path is a directory, not an executable: %s", path)
+	}
+
+	if runnableExtensions[strings.ToLower(filepath.Ext(path))] || isPEFile(path) {
+		if _, _, err := scriptCommand(path, nil); err != nil {
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("file is not an executable or script (.exe, .bat, .cmd or .ps1; scripts need cmd.exe or powershell.exe): %s", path)
+}
+
+// isPEFile reports whether path starts with the "MZ" header of a Windows executable
+func isPEFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return string(header) == "MZ"
+}
+
+// validateWorkingDir checks that dir is a directory or can be created under its nearest existing parent
+func validateWorkingDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("working directory is a file: %s", dir)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("cannot access working directory: %v", err)
+	}
+
+	parent := filepath.Dir(filepath.Clean(dir))
+	for {
+		info, err := os.Stat(parent)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("working directory cannot be created, %s is a file", parent)
+			}
+			return nil
+		}
+		next := filepath.Dir(parent)
+		if next == parent {
+			return fmt.Errorf("working directory cannot be created: %s", dir)
+		}
+		parent = next
+	}
+}
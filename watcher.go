@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// statusWatchInterval is how often the status watcher polls SCM; it matches the status cache TTL
+const statusWatchInterval = 5 * time.Second
+
+// StartStatusWatcher polls the status of all managed services in the background and emits
+// service-status-changed for services changed outside the app (crashes, services.msc, ...)
+func (wsm *WindowsServiceManager) StartStatusWatcher() {
+	wsm.StopStatusWatcher()
+	wsm.seedWatchedStatuses()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	wsm.watcherMutex.Lock()
+	wsm.watcherCancel = cancel
+	wsm.watcherDone = done
+	wsm.watcherMutex.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(statusWatchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				wsm.pollServiceStatuses()
+				wsm.checkLogDiskSpace()
+			}
+		}
+	}()
+}
+
+// StopStatusWatcher stops the background status watcher and waits for it to exit
+func (wsm *WindowsServiceManager) StopStatusWatcher() {
+	wsm.watcherMutex.Lock()
+	cancel, done := wsm.watcherCancel, wsm.watcherDone
+	wsm.watcherCancel, wsm.watcherDone = nil, nil
+	wsm.watcherMutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// watchedStatus is the status last reported for a service through service-status-changed
+type watchedStatus struct {
+	status string
+	pid    int
+}
+
+// seedWatchedStatuses starts the watcher from the statuses services have now
+func (wsm *WindowsServiceManager) seedWatchedStatuses() {
+	wsm.mutex.RLock()
+	defer wsm.mutex.RUnlock()
+
+	for _, service := range wsm.services {
+		wsm.recordWatchedStatus(service.ID, service.Status, service.PID)
+	}
+}
+
+// recordWatchedStatus remembers the status last reported for a service
+func (wsm *WindowsServiceManager) recordWatchedStatus(serviceID, status string, pid int) {
+	wsm.watcherMutex.Lock()
+	defer wsm.watcherMutex.Unlock()
+
+	if wsm.watchedStatuses == nil {
+		wsm.watchedStatuses = make(map[string]watchedStatus)
+	}
+	wsm.watchedStatuses[serviceID] = watchedStatus{status: status, pid: pid}
+}
+
+// lastWatchedStatus returns the status last reported for service, or its stored status
+// when none was reported yet
+func (wsm *WindowsServiceManager) lastWatchedStatus(service *Service) watchedStatus {
+	wsm.watcherMutex.Lock()
+	defer wsm.watcherMutex.Unlock()
+
+	if last, found := wsm.watchedStatuses[service.ID]; found {
+		return last
+	}
+	return watchedStatus{status: service.Status, pid: service.PID}
+}
+
+// pruneWatchedStatuses forgets services that are no longer managed; the caller holds the lock
+func (wsm *WindowsServiceManager) pruneWatchedStatuses() {
+	wsm.watcherMutex.Lock()
+	defer wsm.watcherMutex.Unlock()
+
+	for serviceID := range wsm.watchedStatuses {
+		if _, exists := wsm.services[serviceID]; !exists {
+			delete(wsm.watchedStatuses, serviceID)
+		}
+	}
+}
+
+// pollServiceStatuses refreshes the status of every managed service and emits changes.
+// Changes are detected against the status last reported, not service.Status, because
+// GetServices refreshes service.Status without reporting anything.
+func (wsm *WindowsServiceManager) pollServiceStatuses() {
+	wsm.mutex.Lock()
+	defer wsm.mutex.Unlock()
+
+	changed := false
+
+	err := wsm.withSCM(func(scm scmConnection) error {
+		for _, service := range wsm.services {
+			// Query SCM directly, a cached status would hide changes made outside the app
+			status, pid := queryServiceStatus(scm, service.ID)
+			last := wsm.lastWatchedStatus(service)
+			if status == last.status && pid == last.pid {
+				continue
+			}
+
+			// The cache still holds the old status, drop it so GetServices re-queries
+			wsm.statusCache.Invalidate(service.ID)
+
+			if status == "running" && pid != 0 && pid != last.pid {
+				if startedAt, err := processCreationTime(pid); err == nil {
+					service.StartedAt = startedAt
+				}
+			} else if status != "running" {
+				service.StartedAt = time.Time{}
+			}
+
+			crashed := stoppedUnexpectedly(last, status)
+
+			service.Status = status
+			service.PID = pid
+			service.UpdatedAt = time.Now()
+			changed = true
+
+			if crashed && wsm.notifyOnCrash {
+				loadLastExit(service)
+				notifyServiceCrashed(service)
+			}
+
+			wsm.emitServiceStatusChanged(service.ID, status, pid)
+		}
+		wsm.pruneWatchedStatuses()
+		return nil
+	})
+
+	if err != nil {
+		fmt.Printf("Warning: failed to poll service status: %v\n", err)
+		return
+	}
+
+	if changed {
+		wsm.saveServices()
+	}
+}
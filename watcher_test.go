@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestPollServiceStatusesSeesChangesAfterGetServices(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "running")
+	service.PID = fakeServicePID
+	wsm.seedWatchedStatuses()
+
+	// The service goes down outside the app and a GetServices refresh sees it first
+	fake.status = fakeStatus(svc.Stopped, 0)
+	if _, err := wsm.GetServices(); err != nil {
+		t.Fatalf("GetServices: %v", err)
+	}
+	if service.Status != "stopped" {
+		t.Fatalf("GetServices left status %s, want stopped", service.Status)
+	}
+
+	wsm.pollServiceStatuses()
+
+	if last := wsm.lastWatchedStatus(service); last.status != "stopped" || last.pid != 0 {
+		t.Errorf("watcher last reported %s with PID %d, want stopped with no PID", last.status, last.pid)
+	}
+}
+
+func TestPollServiceStatusesIgnoresChangesMadeByTheApp(t *testing.T) {
+	fake := newFakeService("WSM_app", svc.Running)
+	wsm := newTestManager(t, newFakeConnector(fake))
+	service := addTestService(wsm, "WSM_app", "running")
+	service.PID = fakeServicePID
+	wsm.seedWatchedStatuses()
+
+	if err := wsm.stopService("WSM_app"); err != nil {
+		t.Fatalf("stopService: %v", err)
+	}
+	stoppedAt := service.UpdatedAt
+
+	wsm.pollServiceStatuses()
+
+	if !service.UpdatedAt.Equal(stoppedAt) {
+		t.Errorf("watcher reported a change for a stop the app already reported")
+	}
+}
+
+func TestPollServiceStatusesForgetsRemovedServices(t *testing.T) {
+	wsm := newTestManager(t, newFakeConnector())
+	wsm.recordWatchedStatus("WSM_gone", "running", fakeServicePID)
+
+	wsm.pollServiceStatuses()
+
+	if _, found := wsm.watchedStatuses["WSM_gone"]; found {
+		t.Errorf("watcher still tracks a service that is no longer managed")
+	}
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/registry"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/debug"
@@ -21,7 +26,65 @@ type EmbeddedServiceWrapper struct {
 	config      ServiceConfig
 	process     *exec.Cmd
 	isRunning   bool
-	logFile   	*os.File
+	logWriter   *rotatingWriter
+	errorWriter *rotatingWriter // stderr log when ErrorLogPath is set, nil otherwise
+	exited      chan struct{}   // closed once the target process has exited
+	startedAt   time.Time
+	restarts    int
+	extras      []*extraProcess
+	elog        debug.Log      // Windows Event Log, nil if it could not be opened
+	job         windows.Handle // kill-on-close job holding the target and its children, 0 if none
+
+	lastActivity atomic.Int64 // unix nanoseconds of the last observed target activity
+	lastCPUTime  int64
+}
+
+// extraProcess is a companion process started alongside the main target
+type extraProcess struct {
+	cmd    *exec.Cmd
+	exited chan struct{}
+}
+
+// defaultMaxRestarts is how often a crashed target is restarted when MaxRestarts is not set
+const defaultMaxRestarts = 3
+
+// restartResetAfter is how long a target must run before its restart count starts over
+const restartResetAfter = time.Minute
+
+// defaultStopTimeoutSec is how long the target gets to exit after a graceful stop request
+const defaultStopTimeoutSec = 10
+
+// Idle criteria supported by the wrapper
+const (
+	idleCriterionLog = "log"
+	idleCriterionCPU = "cpu"
+)
+
+// validateIdleSettings checks an idle timeout configuration
+func validateIdleSettings(timeout time.Duration, criterion string) error {
+	if timeout < 0 {
+		return fmt.Errorf("idle timeout must not be negative")
+	}
+	if timeout > 0 && timeout < time.Minute {
+		return fmt.Errorf("idle timeout must be at least one minute")
+	}
+	switch criterion {
+	case "", idleCriterionLog, idleCriterionCPU:
+		return nil
+	default:
+		return fmt.Errorf("unknown idle criterion: %s (use %q or %q)", criterion, idleCriterionLog, idleCriterionCPU)
+	}
+}
+
+// activityWriter records the time of every write as target activity
+type activityWriter struct {
+	w    io.Writer
+	last *atomic.Int64
+}
+
+func (aw *activityWriter) Write(p []byte) (int, error) {
+	aw.last.Store(time.Now().UnixNano())
+	return aw.w.Write(p)
 }
 
 // NewEmbeddedServiceWrapper creates a built-in service wrapper
@@ -41,16 +104,19 @@ func (esw *EmbeddedServiceWrapper) Execute(args []string, r <-chan svc.ChangeReq
 
 	err := esw.startTargetProcess()
 	if err != nil {
-		log.Printf("Failed to start target process: %v", err)
+		esw.logEvent(eventError, eventIDStartFailed, "Failed to start target process %s: %v", esw.config.ExePath, err)
 		s <- svc.Status{State: svc.Stopped}
 		return false, 1
 	}
 
 	s <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
-	log.Printf("Service started, target process PID: %d", esw.process.Process.Pid)
+	esw.logEvent(eventInfo, eventIDStarted, "Service started, target process %s PID: %d", esw.config.ExePath, esw.process.Process.Pid)
 
 	go esw.monitorTargetProcess()
 
+	idleCheck := time.Now()
+	esw.restarts = 0
+
 	for {
 		select {
 		case c := <-r:
@@ -59,6 +125,7 @@ func (esw *EmbeddedServiceWrapper) Execute(args []string, r <-chan svc.ChangeReq
 				log.Printf("Service received stop signal: %s", esw.serviceName)
 				s <- svc.Status{State: svc.StopPending}
 				esw.stopTargetProcess()
+				esw.logEvent(eventInfo, eventIDStopped, "Service stopped: %s", esw.serviceName)
 				s <- svc.Status{State: svc.Stopped}
 				return false, 0
 			case svc.Interrogate:
@@ -68,93 +135,417 @@ func (esw *EmbeddedServiceWrapper) Execute(args []string, r <-chan svc.ChangeReq
 			}
 		default:
 			if !esw.isRunning {
-				log.Printf("Target process exited, stopping service: %s", esw.serviceName)
-				s <- svc.Status{State: svc.Stopped}
-				return false, 0
+				switch esw.restartTarget(r, s) {
+				case targetRestarted:
+					continue
+				case targetFailed:
+					// A service-specific exit code marks the stop as a failure, so SCM runs the recovery actions
+					s <- svc.Status{State: svc.Stopped}
+					return true, 1
+				default:
+					s <- svc.Status{State: svc.Stopped}
+					return false, 0
+				}
+			}
+			if esw.config.IdleTimeout > 0 && time.Since(idleCheck) >= 10*time.Second {
+				idleCheck = time.Now()
+				if esw.isIdle() {
+					esw.logEvent(eventInfo, eventIDStopped, "Target idle for %v, stopping service: %s", esw.config.IdleTimeout, esw.serviceName)
+					s <- svc.Status{State: svc.StopPending}
+					esw.stopTargetProcess()
+					s <- svc.Status{State: svc.Stopped}
+					return false, 0
+				}
 			}
 			time.Sleep(1 * time.Second)
 		}
 	}
 }
 
+// restartOutcome is what restartTarget did about a target that exited on its own
+type restartOutcome int
+
+const (
+	targetRestarted     restartOutcome = iota
+	targetStopRequested                // a stop request arrived while waiting to restart
+	targetExited                       // the target exited with code 0 and RestartOnExit is off
+	targetFailed                       // the target failed and is not restarted (again)
+)
+
+// restartTarget restarts a target that exited on its own, if RestartOnExit allows it.
+// It waits with an increasing backoff while still honouring stop requests. Any outcome
+// other than targetRestarted means the service should stop.
+func (esw *EmbeddedServiceWrapper) restartTarget(r <-chan svc.ChangeRequest, s chan<- svc.Status) restartOutcome {
+	if !esw.config.RestartOnExit {
+		log.Printf("Target process exited, stopping service: %s", esw.serviceName)
+		if esw.process != nil && esw.process.ProcessState != nil && esw.process.ProcessState.ExitCode() == 0 {
+			return targetExited
+		}
+		return targetFailed
+	}
+
+	maxRestarts := esw.config.MaxRestarts
+	if maxRestarts <= 0 {
+		maxRestarts = defaultMaxRestarts
+	}
+	if time.Since(esw.startedAt) >= restartResetAfter {
+		esw.restarts = 0
+	}
+	if esw.restarts >= maxRestarts {
+		esw.logEvent(eventError, eventIDStopped, "Target process exited, giving up after %d restarts: %s", esw.restarts, esw.serviceName)
+		return targetFailed
+	}
+	esw.restarts++
+
+	backoff := time.Duration(esw.restarts) * 2 * time.Second
+	esw.logEvent(eventWarning, eventIDRestarting, "Target process exited unexpectedly, restarting in %v (attempt %d of %d)", backoff, esw.restarts, maxRestarts)
+
+	s <- svc.Status{State: svc.StartPending}
+
+	deadline := time.After(backoff)
+	for waiting := true; waiting; {
+		select {
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Stop, svc.Shutdown:
+				log.Printf("Service received stop signal while restarting: %s", esw.serviceName)
+				return targetStopRequested
+			case svc.Interrogate:
+				s <- c.CurrentStatus
+			}
+		case <-deadline:
+			waiting = false
+		}
+	}
+
+	if err := esw.startTargetProcess(); err != nil {
+		esw.logEvent(eventError, eventIDStartFailed, "Failed to restart target process %s: %v", esw.config.ExePath, err)
+		return esw.restartTarget(r, s)
+	}
+
+	s <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	esw.logEvent(eventInfo, eventIDStarted, "Target process restarted, PID: %d", esw.process.Process.Pid)
+
+	go esw.monitorTargetProcess()
+	return targetRestarted
+}
+
 // startTargetProcess starts the target program
 func (esw *EmbeddedServiceWrapper) startTargetProcess() error {
-	var args []string
-	if esw.config.Args != "" {
-		args = strings.Fields(esw.config.Args)
+	program, args, err := scriptCommand(expandServicePath(esw.config.ExePath), esw.config.ArgsList)
+	if err != nil {
+		return err
 	}
 
-	esw.process = exec.Command(esw.config.ExePath, args...)
+	esw.process = exec.Command(program, args...)
+
+	esw.process.Dir = serviceWorkingDir(esw.config.WorkingDir, esw.config.ExePath)
 
-	workingDir := esw.config.WorkingDir
-	if workingDir == "" {
-		workingDir = filepath.Dir(esw.config.ExePath)
+	if len(esw.config.Env) > 0 {
+		esw.process.Env = os.Environ()
+		for key, value := range esw.config.Env {
+			esw.process.Env = append(esw.process.Env, key+"="+value)
+		}
 	}
-	esw.process.Dir = workingDir
 
 	esw.process.SysProcAttr = &syscall.SysProcAttr{
 		HideWindow: true,
 	}
 
-    // ---- NEW: Set up log redirection ----
-    if esw.config.LogPath != "" {
-        // Ensure log directory exists
-        logDir := filepath.Dir(esw.config.LogPath)
-        if err := os.MkdirAll(logDir, 0755); err != nil {
-            return fmt.Errorf("failed to create log directory: %w", err)
-        }
-        // Open log file (append, create if missing)
-        logFile, err := os.OpenFile(esw.config.LogPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-        if err != nil {
-            return fmt.Errorf("failed to open log file: %w", err)
-        }
-        esw.process.Stdout = logFile
-        esw.process.Stderr = logFile
-        // Store the file so we can close it later
-        esw.logFile = logFile
-    } else {
-        // Fallback: discard output (or log to Windows event log)
-        esw.process.Stdout = nil
-        esw.process.Stderr = nil
-    }
+	// ---- NEW: Set up log redirection ----
+	if esw.config.LogPath != "" {
+		logWriter, err := esw.openLog(esw.config.LogPath)
+		if err != nil {
+			return err
+		}
+		esw.process.Stdout = logWriter
+		esw.process.Stderr = logWriter
+		// Store the writer so we can close it later
+		esw.logWriter = logWriter
+	} else {
+		// Fallback: discard output (or log to Windows event log)
+		esw.process.Stdout = nil
+		esw.process.Stderr = nil
+	}
+
+	// Error output gets a file of its own so it can be read without the regular output
+	if esw.config.ErrorLogPath != "" {
+		errorWriter, err := esw.openLog(esw.config.ErrorLogPath)
+		if err != nil {
+			esw.closeLogs()
+			return err
+		}
+		esw.process.Stderr = errorWriter
+		esw.errorWriter = errorWriter
+	}
+
+	// Output counts as activity when idle detection watches the log
+	if esw.config.IdleTimeout > 0 && esw.config.IdleCriterion != idleCriterionCPU {
+		var out, errOut io.Writer = io.Discard, io.Discard
+		if esw.logWriter != nil {
+			out, errOut = esw.logWriter, esw.logWriter
+		}
+		if esw.errorWriter != nil {
+			errOut = esw.errorWriter
+		}
+		esw.process.Stdout = &activityWriter{w: out, last: &esw.lastActivity}
+		esw.process.Stderr = &activityWriter{w: errOut, last: &esw.lastActivity}
+	}
 
 	esw.process.SysProcAttr = &syscall.SysProcAttr{
-        HideWindow: true, // still hide the target's window
-    }
+		HideWindow: true, // still hide the target's window
+	}
 
-	err := esw.process.Start()
+	err = esw.process.Start()
 	if err != nil {
+		esw.closeLogs()
 		return fmt.Errorf("failed to start target process: %v", err)
 	}
 
+	esw.exited = make(chan struct{})
+	esw.startedAt = time.Now()
+
+	// Children left behind by a previous run go with their job, then the new target gets its own
+	// so stopping it reliably takes its whole process tree along
+	esw.closeJob()
+	if job, err := newKillOnCloseJob(esw.process.Process.Pid); err != nil {
+		log.Printf("Child processes will be stopped by walking the process tree: %v", err)
+	} else {
+		esw.job = job
+	}
+
+	if err := esw.startExtraCommands(); err != nil {
+		esw.process.Process.Kill()
+		esw.process.Wait()
+		esw.closeJob()
+		esw.closeLogs()
+		return err
+	}
+
 	esw.isRunning = true
+	esw.lastActivity.Store(time.Now().UnixNano())
+	esw.lastCPUTime = 0
 	log.Printf("Target process started: %s, PID: %d", esw.config.ExePath, esw.process.Process.Pid)
 	return nil
 }
 
-// stopTargetProcess stops the target program
+// openLog opens a log file of the target for appending, rotating it once it grows too large
+func (esw *EmbeddedServiceWrapper) openLog(path string) (*rotatingWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	writer, err := openRotatingWriter(path, esw.config.LogMaxSizeMB, resolveLogMaxBackups(esw.config.LogMaxBackups))
+	if err != nil {
+		return nil, err
+	}
+	writer.onDiskFull = func(err error) {
+		esw.logEvent(eventWarning, eventIDLogDiskFull, "Log disk is full, output is dropped until space is freed: %s: %v", path, err)
+	}
+	return writer, nil
+}
+
+// closeLogs closes the log files of the target
+func (esw *EmbeddedServiceWrapper) closeLogs() {
+	if esw.logWriter != nil {
+		esw.logWriter.Close()
+		esw.logWriter = nil
+	}
+	if esw.errorWriter != nil {
+		esw.errorWriter.Close()
+		esw.errorWriter = nil
+	}
+}
+
+// stopTargetProcess stops the target program and every process it started.
+// The target is first asked to exit (Ctrl-C for console programs, WM_CLOSE for windowed ones)
+// and only killed if it is still running after the stop timeout.
 func (esw *EmbeddedServiceWrapper) stopTargetProcess() {
 	if esw.process != nil && esw.isRunning {
-		log.Printf("Stopping target process, PID: %d", esw.process.Process.Pid)
+		pid := esw.process.Process.Pid
+		log.Printf("Stopping target process, PID: %d", pid)
+
+		// Without a job the children have to be found while the target is still there to find them by
+		var tree []ProcessInfo
+		if esw.job == 0 {
+			var err error
+			if tree, err = processTree(pid); err != nil {
+				log.Printf("Failed to list child processes: %v", err)
+			}
+		}
+		defer func() {
+			esw.closeJob()
+			killDescendants(tree)
+		}()
+
+		timeout := esw.stopTimeout()
+
+		if requestProcessExit(pid, timeout) {
+			select {
+			case <-esw.exited:
+				log.Printf("Target process exited gracefully")
+				return
+			case <-time.After(timeout):
+				log.Printf("Target process did not exit within %v, killing it", timeout)
+			}
+		}
 
 		esw.process.Process.Kill()
 
-		esw.process.Wait()
-		esw.isRunning = false
+		<-esw.exited
 		log.Printf("Target process stopped")
 	}
 }
 
+// stopTimeout returns how long a process may take to exit after being asked to
+func (esw *EmbeddedServiceWrapper) stopTimeout() time.Duration {
+	if esw.config.StopTimeoutSec <= 0 {
+		return defaultStopTimeoutSec * time.Second
+	}
+	return time.Duration(esw.config.StopTimeoutSec) * time.Second
+}
+
+// requestProcessExit asks a process to exit (Ctrl-C for console programs, WM_CLOSE for windowed ones).
+// It returns false if the process offers no way to be asked.
+func requestProcessExit(pid int, timeout time.Duration) bool {
+	err := sendCtrlC(pid)
+	if err == nil {
+		log.Printf("Sent Ctrl-C to process %d, waiting up to %v", pid, timeout)
+		return true
+	}
+	if postCloseToWindows(pid) > 0 {
+		log.Printf("Sent WM_CLOSE to process %d, waiting up to %v", pid, timeout)
+		return true
+	}
+	log.Printf("Process %d cannot be asked to exit: %v", pid, err)
+	return false
+}
+
+// startExtraCommands starts the companion processes configured next to the main target.
+// Their output goes to the same log as the target.
+func (esw *EmbeddedServiceWrapper) startExtraCommands() error {
+	esw.extras = nil
+
+	for _, spec := range esw.config.ExtraCommands {
+		args, err := splitArgs(spec.Args)
+		if err != nil {
+			esw.stopExtraCommands()
+			return fmt.Errorf("invalid arguments for extra command %s: %v", spec.ExePath, err)
+		}
+
+		program, args, err := scriptCommand(expandServicePath(spec.ExePath), args)
+		if err != nil {
+			esw.stopExtraCommands()
+			return fmt.Errorf("cannot run extra command %s: %v", spec.ExePath, err)
+		}
+
+		cmd := exec.Command(program, args...)
+		cmd.Dir = serviceWorkingDir(spec.WorkingDir, spec.ExePath)
+		cmd.Env = esw.process.Env
+		cmd.Stdout = esw.process.Stdout
+		cmd.Stderr = esw.process.Stderr
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			HideWindow: true,
+		}
+
+		if err := cmd.Start(); err != nil {
+			esw.stopExtraCommands()
+			return fmt.Errorf("failed to start extra command %s: %v", spec.ExePath, err)
+		}
+
+		extra := &extraProcess{cmd: cmd, exited: make(chan struct{})}
+		esw.extras = append(esw.extras, extra)
+		log.Printf("Extra command started: %s, PID: %d", spec.ExePath, cmd.Process.Pid)
+
+		go func() {
+			extra.cmd.Wait()
+			close(extra.exited)
+			log.Printf("Extra command exited: %s", extra.cmd.Path)
+		}()
+	}
+
+	return nil
+}
+
+// stopExtraCommands asks all companion processes to exit and kills those that do not
+func (esw *EmbeddedServiceWrapper) stopExtraCommands() {
+	if len(esw.extras) == 0 {
+		return
+	}
+
+	timeout := esw.stopTimeout()
+	for _, extra := range esw.extras {
+		select {
+		case <-extra.exited:
+		default:
+			requestProcessExit(extra.cmd.Process.Pid, timeout)
+		}
+	}
+
+	deadline := time.After(timeout)
+	for _, extra := range esw.extras {
+		select {
+		case <-extra.exited:
+		case <-deadline:
+			log.Printf("Extra command did not exit within %v, killing it: %s", timeout, extra.cmd.Path)
+			extra.cmd.Process.Kill()
+			<-extra.exited
+		}
+	}
+
+	esw.extras = nil
+}
+
+// isIdle reports whether the target has shown no activity for the configured idle timeout.
+// With the "cpu" criterion, any CPU time consumed since the last check counts as activity.
+func (esw *EmbeddedServiceWrapper) isIdle() bool {
+	if esw.config.IdleCriterion == idleCriterionCPU && esw.process != nil && esw.process.Process != nil {
+		if cpuTime, err := processCPUTime(esw.process.Process.Pid); err == nil && cpuTime != esw.lastCPUTime {
+			esw.lastCPUTime = cpuTime
+			esw.lastActivity.Store(time.Now().UnixNano())
+		}
+	}
+
+	last := time.Unix(0, esw.lastActivity.Load())
+	return time.Since(last) >= esw.config.IdleTimeout
+}
+
 // monitorTargetProcess monitors the target process
 func (esw *EmbeddedServiceWrapper) monitorTargetProcess() {
 	if esw.process != nil {
 		esw.process.Wait()
+		pid := esw.process.Process.Pid
+		exitCode := esw.process.ProcessState.ExitCode()
 		esw.isRunning = false
-		if esw.logFile != nil {
-            esw.logFile.Close()
-            esw.logFile = nil
-        }
-		log.Printf("Target process exited: %s", esw.config.ExePath)
+		// The main target decides the service lifetime, so its companions go with it
+		esw.stopExtraCommands()
+		esw.closeLogs()
+		close(esw.exited)
+
+		recordTargetExit(esw.serviceName, exitCode, time.Now())
+
+		level := eventInfo
+		if exitCode != 0 {
+			level = eventWarning
+		}
+		esw.logEvent(level, eventIDExited, "Target process %s (PID %d) exited with code %d", esw.config.ExePath, pid, exitCode)
+	}
+}
+
+// recordTargetExit stores the exit code and time of the target in the service's Parameters key
+func recordTargetExit(serviceName string, exitCode int, exitTime time.Time) {
+	keyPath := fmt.Sprintf(`SYSTEM\CurrentControlSet\Services\%s\Parameters`, serviceName)
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.SET_VALUE)
+	if err != nil {
+		log.Printf("Failed to record exit code: %v", err)
+		return
+	}
+	defer key.Close()
+
+	if err := key.SetStringValue("LastExitCode", strconv.Itoa(exitCode)); err != nil {
+		log.Printf("Failed to record exit code: %v", err)
+	}
+	if err := key.SetStringValue("LastExitTime", exitTime.Format(time.RFC3339)); err != nil {
+		log.Printf("Failed to record exit time: %v", err)
 	}
 }
 
@@ -167,6 +558,11 @@ func RunAsWindowsService(serviceName string, config ServiceConfig) error {
 		return fmt.Errorf("failed to check service status: %v", err)
 	}
 
+	wrapper.elog = openWrapperEventLog(serviceName, isService)
+	if wrapper.elog != nil {
+		defer wrapper.elog.Close()
+	}
+
 	if isService {
 		log.Printf("Running as Windows service: %s", serviceName)
 		err = svc.Run(serviceName, wrapper)
@@ -211,6 +607,19 @@ func LoadServiceConfigFromRegistry(serviceName string) (*ServiceConfig, error) {
 	if err != nil {
 		args = ""
 	}
+	// Services created before ArgsList existed only have the flat Args string
+	var argsList []string
+	if value, _, err := key.GetStringValue("ArgsList"); err == nil && value != "" {
+		if err := json.Unmarshal([]byte(value), &argsList); err != nil {
+			log.Printf("Ignoring invalid ArgsList: %v", err)
+		}
+	}
+	if argsList == nil {
+		if argsList, err = splitArgs(args); err != nil {
+			log.Printf("Failed to parse Args, splitting on spaces: %v", err)
+			argsList = strings.Fields(args)
+		}
+	}
 	workingDir, _, err := key.GetStringValue("WorkingDir")
 	if err != nil {
 		workingDir = ""
@@ -223,12 +632,100 @@ func LoadServiceConfigFromRegistry(serviceName string) (*ServiceConfig, error) {
 	if err != nil {
 		logPath = ""
 	}
+	// StderrLog only names a file of its own when it differs from StdoutLog
+	errorLogPath, _, err := key.GetStringValue("StderrLog")
+	if err != nil || strings.EqualFold(errorLogPath, logPath) {
+		errorLogPath = ""
+	}
+	var idleTimeout time.Duration
+	if value, _, err := key.GetStringValue("IdleTimeout"); err == nil {
+		idleTimeout, _ = time.ParseDuration(value)
+	}
+	idleCriterion, _, err := key.GetStringValue("IdleCriterion")
+	if err != nil {
+		idleCriterion = ""
+	}
+	var extraCommands []CommandSpec
+	if value, _, err := key.GetStringValue("ExtraCommands"); err == nil && value != "" {
+		if err := json.Unmarshal([]byte(value), &extraCommands); err != nil {
+			log.Printf("Ignoring invalid ExtraCommands: %v", err)
+		}
+	}
+	var restartOnExit bool
+	if value, _, err := key.GetStringValue("RestartOnExit"); err == nil {
+		restartOnExit, _ = strconv.ParseBool(value)
+	}
+	var env map[string]string
+	if value, _, err := key.GetStringValue("Env"); err == nil && value != "" {
+		// Services created before Env was protected hold plaintext, which unprotectSecret passes through
+		if value, err = unprotectSecret(value); err != nil {
+			log.Printf("Ignoring Env: %v", err)
+		} else if err := json.Unmarshal([]byte(value), &env); err != nil {
+			log.Printf("Ignoring invalid Env: %v", err)
+		}
+	}
+
+	healthCheckType, _, _ := key.GetStringValue("HealthCheckType")
+	healthCheckTarget, _, _ := key.GetStringValue("HealthCheckTarget")
+	var healthCheckIntervalSec int
+	if value, _, err := key.GetStringValue("HealthCheckIntervalSec"); err == nil {
+		healthCheckIntervalSec, _ = strconv.Atoi(value)
+	}
+
+	var maxRestarts int
+	if value, _, err := key.GetStringValue("MaxRestarts"); err == nil {
+		maxRestarts, _ = strconv.Atoi(value)
+	}
+	var stopTimeoutSec, startTimeoutSec int
+	if value, _, err := key.GetStringValue("StopTimeoutSec"); err == nil {
+		stopTimeoutSec, _ = strconv.Atoi(value)
+	}
+	if value, _, err := key.GetStringValue("StartTimeoutSec"); err == nil {
+		startTimeoutSec, _ = strconv.Atoi(value)
+	}
+	var logMaxSizeMB int
+	if value, _, err := key.GetStringValue("LogMaxSizeMB"); err == nil {
+		logMaxSizeMB, _ = strconv.Atoi(value)
+	}
+	var logMaxBackups *int
+	if value, _, err := key.GetStringValue("LogMaxBackups"); err == nil {
+		logMaxBackups = parseLogMaxBackups(value)
+	}
+	logEncoding, _, err := key.GetStringValue("LogEncoding")
+	if err != nil {
+		logEncoding = ""
+	}
+	var listenPort int
+	if value, _, err := key.GetStringValue("ListenPort"); err == nil {
+		listenPort, _ = strconv.Atoi(value)
+	}
 
 	return &ServiceConfig{
-		Name:       displayName,
-		ExePath:    exePath,
-		Args:       args,
-		WorkingDir: workingDir,
-		LogPath:    logPath,
+		Name:         displayName,
+		ExePath:      exePath,
+		Args:         args,
+		ArgsList:     argsList,
+		WorkingDir:   workingDir,
+		LogPath:      logPath,
+		ErrorLogPath: errorLogPath,
+
+		LogMaxSizeMB:  logMaxSizeMB,
+		LogMaxBackups: logMaxBackups,
+		LogEncoding:   logEncoding,
+		ListenPort:    listenPort,
+
+		StopTimeoutSec:  stopTimeoutSec,
+		StartTimeoutSec: startTimeoutSec,
+		RestartOnExit:   restartOnExit,
+		MaxRestarts:     maxRestarts,
+		ExtraCommands:   extraCommands,
+		Env:             env,
+
+		IdleTimeout:   idleTimeout,
+		IdleCriterion: idleCriterion,
+
+		HealthCheckType:        healthCheckType,
+		HealthCheckTarget:      healthCheckTarget,
+		HealthCheckIntervalSec: healthCheckIntervalSec,
 	}, nil
 }
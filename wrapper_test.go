@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// exitedTarget returns a target process that has already exited with exitCode
+func exitedTarget(t *testing.T, exitCode string) *exec.Cmd {
+	t.Helper()
+
+	cmd := exec.Command("cmd.exe", "/c", "exit", exitCode)
+	cmd.Run()
+	if cmd.ProcessState == nil {
+		t.Fatalf("failed to run cmd.exe")
+	}
+	return cmd
+}
+
+func TestRestartTargetGivingUpIsAFailure(t *testing.T) {
+	esw := NewEmbeddedServiceWrapper("WSM_app", ServiceConfig{RestartOnExit: true, MaxRestarts: 2})
+	esw.process = exitedTarget(t, "3")
+	esw.restarts = 2
+
+	if got := esw.restartTarget(make(chan svc.ChangeRequest), make(chan svc.Status, 1)); got != targetFailed {
+		t.Errorf("restartTarget = %v, want targetFailed", got)
+	}
+}
+
+func TestRestartTargetWithoutRestartOnExit(t *testing.T) {
+	tests := []struct {
+		exitCode string
+		want     restartOutcome
+	}{
+		{"0", targetExited},
+		{"3", targetFailed},
+	}
+
+	for _, tt := range tests {
+		esw := NewEmbeddedServiceWrapper("WSM_app", ServiceConfig{})
+		esw.process = exitedTarget(t, tt.exitCode)
+
+		if got := esw.restartTarget(make(chan svc.ChangeRequest), make(chan svc.Status, 1)); got != tt.want {
+			t.Errorf("restartTarget after exit code %s = %v, want %v", tt.exitCode, got, tt.want)
+		}
+	}
+}
+
+func TestRestartTargetHonoursStop(t *testing.T) {
+	esw := NewEmbeddedServiceWrapper("WSM_app", ServiceConfig{RestartOnExit: true})
+	esw.process = exitedTarget(t, "3")
+
+	r := make(chan svc.ChangeRequest, 1)
+	r <- svc.ChangeRequest{Cmd: svc.Stop}
+
+	if got := esw.restartTarget(r, make(chan svc.Status, 1)); got != targetStopRequested {
+		t.Errorf("restartTarget = %v, want targetStopRequested", got)
+	}
+}
+
+func TestExecuteReportsFailureWhenTargetCrashes(t *testing.T) {
+	esw := NewEmbeddedServiceWrapper("WSM_app", ServiceConfig{
+		ExePath:  `C:\Windows\System32\cmd.exe`,
+		ArgsList: []string{"/c", "exit", "3"},
+	})
+
+	statuses := make(chan svc.Status, 10)
+	ssec, errno := esw.Execute(nil, make(chan svc.ChangeRequest), statuses)
+	if !ssec || errno != 1 {
+		t.Errorf("Execute = (%v, %d), want (true, 1) so SCM runs the recovery actions", ssec, errno)
+	}
+}